@@ -0,0 +1,143 @@
+// Package citation форматирует библиографические ссылки на цитаты в стилях APA, MLA и BibTeX.
+package citation
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrUnknownStyle = errors.New("unknown citation style")
+
+// Поддерживаемые стили
+const (
+	StyleAPA    = "apa"
+	StyleMLA    = "mla"
+	StyleBibTeX = "bibtex"
+)
+
+var Styles = []string{StyleAPA, StyleMLA, StyleBibTeX}
+
+// Типы источников
+const (
+	SourceBook      = "book"
+	SourceSpeech    = "speech"
+	SourceArticle   = "article"
+	SourceInterview = "interview"
+	SourceLetter    = "letter"
+	SourceFilm      = "film"
+	SourceWebsite   = "website"
+	SourceOther     = "other"
+)
+
+// Work - все, что известно об источнике цитаты
+type Work struct {
+	// ID используется в ключе BibTeX
+	ID         uint
+	Author     string
+	Quote      string
+	SourceType string
+	Title      string
+	Year       *int
+	Page       string
+	URL        string
+	// AccessedAt - дата обращения для онлайн-источников (MLA)
+	AccessedAt time.Time
+}
+
+// Format возвращает ссылку на работу в указанном стиле
+func Format(style string, w Work) (string, error) {
+	switch style {
+	case StyleAPA:
+		return formatAPA(w), nil
+	case StyleMLA:
+		return formatMLA(w), nil
+	case StyleBibTeX:
+		return formatBibTeX(w), nil
+	default:
+		return "", ErrUnknownStyle
+	}
+}
+
+// title - название источника или, если его нет, начало самой цитаты
+func (w Work) title() string {
+	if w.Title != "" {
+		return w.Title
+	}
+	return truncateWords(w.Quote, 12)
+}
+
+// standalone - источники, названия которых выделяются как самостоятельные произведения
+func (w Work) standalone() bool {
+	switch w.SourceType {
+	case SourceBook, SourceFilm:
+		return true
+	default:
+		return false
+	}
+}
+
+// nameSuffixes - части имени, которые идут после фамилии
+var nameSuffixes = map[string]bool{"jr": true, "sr": true, "ii": true, "iii": true, "iv": true}
+
+// splitName делит имя на фамилию, остальные имена и суффикс:
+// "Martin Luther King Jr." -> "King", ["Martin", "Luther"], "Jr."
+func splitName(name string) (family string, given []string, suffix string) {
+	parts := strings.Fields(name)
+	if len(parts) > 2 && nameSuffixes[strings.ToLower(strings.Trim(parts[len(parts)-1], ".,"))] {
+		suffix = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+		parts[len(parts)-1] = strings.TrimSuffix(parts[len(parts)-1], ",")
+	}
+	if len(parts) == 0 {
+		return "", nil, ""
+	}
+	return parts[len(parts)-1], parts[:len(parts)-1], suffix
+}
+
+// invertedName - "Фамилия, Имена[, Суффикс]"; givenFormat сокращает имена (например, до инициалов)
+func invertedName(name string, givenFormat func([]string) string) string {
+	family, given, suffix := splitName(name)
+	out := family
+	if len(given) > 0 {
+		out += ", " + givenFormat(given)
+	}
+	if suffix != "" {
+		out += ", " + suffix
+	}
+	return out
+}
+
+func fullNames(given []string) string {
+	return strings.Join(given, " ")
+}
+
+// initials - инициалы имен: ["Albert", "Gustav"] -> "A. G."
+func initials(given []string) string {
+	out := make([]string, 0, len(given))
+	for _, name := range given {
+		name = strings.TrimSuffix(name, ".")
+		if r := []rune(name); len(r) > 0 {
+			out = append(out, string(r[0])+".")
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func truncateWords(s string, n int) string {
+	words := strings.Fields(s)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:n], " ") + "…"
+}
+
+// sentence завершает фрагмент точкой, если он еще не заканчивается знаком препинания
+func sentence(s string) string {
+	last, _ := utf8.DecodeLastRuneInString(s)
+	if s == "" || strings.ContainsRune(".!?…", last) {
+		return s
+	}
+	return s + "."
+}
@@ -0,0 +1,186 @@
+package citation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// formatAPA - APA 7: Фамилия, И. (Год). Название (p. 12). URL
+func formatAPA(w Work) string {
+	var parts []string
+
+	author := invertedName(w.Author, initials)
+	if author == "" {
+		author = "Unknown"
+	}
+	parts = append(parts, sentence(author))
+
+	year := "n.d."
+	if w.Year != nil {
+		year = strconv.Itoa(*w.Year)
+	}
+	parts = append(parts, "("+year+").")
+
+	title := w.title()
+	if w.Title == "" {
+		title = `"` + title + `"`
+	}
+	if w.Page != "" {
+		title += " (p. " + w.Page + ")"
+	}
+	if w.SourceType != "" && !w.standalone() && w.SourceType != SourceOther {
+		title += " [" + capitalize(w.SourceType) + "]"
+	}
+	parts = append(parts, sentence(title))
+
+	if w.URL != "" {
+		parts = append(parts, w.URL)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatMLA - MLA 9: Фамилия, Имя. Название. Год, p. 12. URL. Accessed 1 Jan. 2024.
+func formatMLA(w Work) string {
+	var parts []string
+
+	if author := invertedName(w.Author, fullNames); author != "" {
+		parts = append(parts, sentence(author))
+	}
+
+	// Части больших произведений (статьи, речи, страницы сайтов) берутся в кавычки
+	title := w.title()
+	if !w.standalone() {
+		title = `"` + sentence(title) + `"`
+	} else {
+		title = sentence(title)
+	}
+	parts = append(parts, title)
+
+	var details []string
+	if w.SourceType != "" && !w.standalone() && w.SourceType != SourceOther && w.SourceType != SourceWebsite && w.SourceType != SourceArticle {
+		details = append(details, capitalize(w.SourceType))
+	}
+	if w.Year != nil {
+		details = append(details, strconv.Itoa(*w.Year))
+	}
+	if w.Page != "" {
+		details = append(details, "p. "+w.Page)
+	}
+	if w.URL != "" {
+		details = append(details, strings.TrimPrefix(strings.TrimPrefix(w.URL, "https://"), "http://"))
+	}
+	if len(details) > 0 {
+		parts = append(parts, sentence(strings.Join(details, ", ")))
+	}
+
+	if w.URL != "" && !w.AccessedAt.IsZero() {
+		parts = append(parts, "Accessed "+w.AccessedAt.Format("2 Jan. 2006")+".")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatBibTeX - запись BibTeX; тип записи зависит от типа источника
+func formatBibTeX(w Work) string {
+	entry := "misc"
+	switch w.SourceType {
+	case SourceBook:
+		entry = "book"
+	case SourceArticle:
+		entry = "article"
+	}
+
+	// BibTeX ожидает "Фамилия, Суффикс, Имена"
+	family, given, suffix := splitName(w.Author)
+	author := family
+	if suffix != "" {
+		author += ", " + suffix
+	}
+	if len(given) > 0 {
+		author += ", " + fullNames(given)
+	}
+
+	fields := [][2]string{
+		{"author", author},
+		{"title", w.title()},
+	}
+	if w.Year != nil {
+		fields = append(fields, [2]string{"year", strconv.Itoa(*w.Year)})
+	}
+	if w.Page != "" {
+		fields = append(fields, [2]string{"pages", w.Page})
+	}
+	if w.URL != "" {
+		fields = append(fields, [2]string{"url", w.URL})
+	}
+	if entry == "misc" && w.SourceType != "" {
+		fields = append(fields, [2]string{"howpublished", capitalize(w.SourceType)})
+	}
+	if w.Quote != "" {
+		fields = append(fields, [2]string{"note", "Quote: " + truncateWords(w.Quote, 30)})
+	}
+
+	lines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := field[1]
+		if value == "" {
+			continue
+		}
+		// url выводится как есть: пакеты url/hyperref читают его буквально
+		if field[0] != "url" {
+			value = escapeBibTeX(value)
+		}
+		lines = append(lines, fmt.Sprintf("  %s = {%s}", field[0], value))
+	}
+
+	return fmt.Sprintf("@%s{%s,\n%s\n}", entry, bibtexKey(w, family), strings.Join(lines, ",\n"))
+}
+
+// bibtexKey - ключ записи: фамилия латиницей + год, иначе quote<ID>
+func bibtexKey(w Work, family string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(family) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString("quote")
+		b.WriteString(strconv.FormatUint(uint64(w.ID), 10))
+		return b.String()
+	}
+
+	if w.Year != nil {
+		b.WriteString(strconv.Itoa(*w.Year))
+	} else {
+		b.WriteString(strconv.FormatUint(uint64(w.ID), 10))
+	}
+	return b.String()
+}
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+)
+
+func escapeBibTeX(s string) string {
+	return bibtexEscaper.Replace(s)
+}
+
+func capitalize(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotes-app/contentfilter"
+)
+
+// ContentFilter - проверки текста цитат и комментариев перед сохранением
+var ContentFilter *contentfilter.Pipeline
+
+func InitContentFilter() {
+	ContentFilter = &contentfilter.Pipeline{}
+
+	addRule := func(env string, defaultAction contentfilter.Action, filter func() contentfilter.ContentFilter) {
+		action, enabled, err := contentfilter.ParseAction(getEnv(env, string(defaultAction)))
+		if err != nil {
+			log.Printf("Invalid %s: %v, using %s", env, err, defaultAction)
+			action, enabled = defaultAction, true
+		}
+		if !enabled {
+			log.Printf("Content filter %s: off", strings.ToLower(strings.TrimPrefix(env, "CONTENT_FILTER_")))
+			return
+		}
+		ContentFilter.Rules = append(ContentFilter.Rules, contentfilter.Rule{Filter: filter(), Action: action})
+	}
+
+	addRule("CONTENT_FILTER_WORDLIST", contentfilter.ActionMask, func() contentfilter.ContentFilter {
+		return contentfilter.NewWordlistFilter(splitList(os.Getenv("CONTENT_FILTER_EXTRA_WORDS")))
+	})
+
+	addRule("CONTENT_FILTER_LINKS", contentfilter.ActionQueue, func() contentfilter.ContentFilter {
+		// 0 - допустимое значение (любая ссылка считается спамом), поэтому не getEnvInt
+		maxLinks := 2
+		if value, err := strconv.Atoi(os.Getenv("CONTENT_FILTER_MAX_LINKS")); err == nil && value >= 0 {
+			maxLinks = value
+		}
+		return contentfilter.NewLinkFilter(maxLinks, splitList(os.Getenv("CONTENT_FILTER_BLOCKED_DOMAINS")))
+	})
+
+	// Повтор может быть и честной повторной отправкой, поэтому по умолчанию - на модерацию
+	addRule("CONTENT_FILTER_DUPLICATES", contentfilter.ActionQueue, func() contentfilter.ContentFilter {
+		window := time.Duration(getEnvInt("CONTENT_FILTER_DUPLICATE_WINDOW_HOURS", 24)) * time.Hour
+		similarity := float64(min(getEnvInt("CONTENT_FILTER_DUPLICATE_SIMILARITY", 90), 100)) / 100
+		return contentfilter.NewDuplicateFilter(DB, window, 50, similarity)
+	})
+}
+
+// splitList - значения через запятую без пустых элементов
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log"
+	"time"
+	// Встроенная база часовых поясов: в контейнере может не быть /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// Настройки цитаты дня
+var (
+	// DailyQuoteLocation - часовой пояс, в котором начинается новый день
+	DailyQuoteLocation = time.UTC
+	// DailyQuoteRepeatDays - сколько дней цитата не может повториться в той же ленте
+	DailyQuoteRepeatDays = 30
+	// DailyQuotePoolSize - из скольких лучших по рейтингу цитат делается выбор
+	DailyQuotePoolSize = 50
+)
+
+func InitDailyQuote() {
+	if name := getEnv("DAILY_QUOTE_TZ", ""); name != "" {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			log.Printf("Invalid DAILY_QUOTE_TZ=%q, using UTC: %v", name, err)
+		} else {
+			DailyQuoteLocation = location
+		}
+	}
+
+	DailyQuoteRepeatDays = getEnvInt("DAILY_QUOTE_REPEAT_DAYS", DailyQuoteRepeatDays)
+	DailyQuotePoolSize = getEnvInt("DAILY_QUOTE_POOL_SIZE", DailyQuotePoolSize)
+}
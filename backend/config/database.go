@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -13,15 +14,20 @@ import (
 
 var DB *gorm.DB
 
-func ConnectDatabase() {
+// databaseDSN - строка подключения к Postgres из переменных окружения
+func databaseDSN() string {
 	host := getEnv("DB_HOST", "db")
 	user := getEnv("DB_USER", "postgres")
 	password := getEnv("DB_PASSWORD", "postgres")
 	dbname := getEnv("DB_NAME", "quotes_db")
 	port := getEnv("DB_PORT", "5432")
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		host, user, password, dbname, port)
+}
+
+func ConnectDatabase() {
+	dsn := databaseDSN()
 
 	// Ждем пока база данных станет доступна
 	var database *gorm.DB
@@ -69,3 +75,11 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -9,23 +13,42 @@ import (
 
 var JWTSecret = []byte("your_default_secret")
 
+// Время жизни access и refresh токенов
+var (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 func InitJWT() {
 	secret := os.Getenv("JWT_SECRET")
 	if secret != "" {
 		JWTSecret = []byte(secret)
 	}
+
+	if minutes, err := strconv.Atoi(os.Getenv("JWT_ACCESS_TTL_MINUTES")); err == nil && minutes > 0 {
+		AccessTokenTTL = time.Duration(minutes) * time.Minute
+	}
+	if days, err := strconv.Atoi(os.Getenv("JWT_REFRESH_TTL_DAYS")); err == nil && days > 0 {
+		RefreshTokenTTL = time.Duration(days) * 24 * time.Hour
+	}
 }
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID uint   `json:"sid"`
+	Role      string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateToken выпускает короткоживущий access токен, привязанный к сессии.
+// Роль попадает в claims и обновляется при каждом refresh.
+func GenerateToken(userID, sessionID uint, role string) (string, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL)
 
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
+		Role:      role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -52,3 +75,18 @@ func ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// GenerateRandomToken создает непрозрачный случайный токен (refresh, сброс пароля и т.п.)
+func GenerateRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken возвращает SHA-256 хеш токена; в БД храним только хеши
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
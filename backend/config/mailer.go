@@ -0,0 +1,48 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotes-app/mailer"
+)
+
+var Mailer mailer.Mailer
+
+// AppBaseURL используется в ссылках из писем
+var AppBaseURL = "http://localhost:8080"
+
+// Время жизни токенов сброса пароля и подтверждения email
+var (
+	PasswordResetTTL     = time.Hour
+	EmailVerificationTTL = 24 * time.Hour
+)
+
+func InitMailer() {
+	AppBaseURL = strings.TrimRight(getEnv("APP_BASE_URL", AppBaseURL), "/")
+
+	if minutes, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES")); err == nil && minutes > 0 {
+		PasswordResetTTL = time.Duration(minutes) * time.Minute
+	}
+	if hours, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL_HOURS")); err == nil && hours > 0 {
+		EmailVerificationTTL = time.Duration(hours) * time.Hour
+	}
+
+	switch getEnv("MAILER_DRIVER", "log") {
+	case "smtp":
+		Mailer = mailer.NewSMTPMailer(
+			getEnv("SMTP_HOST", "localhost"),
+			getEnv("SMTP_PORT", "587"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			getEnv("SMTP_FROM", "no-reply@quotes.app"),
+		)
+		log.Println("Mailer: SMTP")
+	default:
+		Mailer = mailer.NewLogMailer(os.Getenv("MAILER_LOG_FILE"))
+		log.Println("Mailer: log")
+	}
+}
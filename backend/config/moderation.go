@@ -0,0 +1,18 @@
+package config
+
+// ReportHideThreshold - после скольких нерассмотренных жалоб контент скрывается до проверки модератором
+var ReportHideThreshold = 3
+
+// QuoteDuplicateSimilarity - порог триграммной похожести (pg_trgm), начиная с которого цитаты считаются дублями
+var QuoteDuplicateSimilarity = 0.8
+
+// minQuoteDuplicateSimilarity - нижняя граница порога в процентах. Кандидатов отбирает
+// оператор %, он использует pg_trgm.similarity_threshold (по умолчанию 0.3),
+// поэтому более низкий порог молча терял бы совпадения
+const minQuoteDuplicateSimilarity = 30
+
+func InitModeration() {
+	ReportHideThreshold = getEnvInt("REPORT_HIDE_THRESHOLD", ReportHideThreshold)
+	percent := min(max(getEnvInt("QUOTE_DUPLICATE_SIMILARITY", 80), minQuoteDuplicateSimilarity), 100)
+	QuoteDuplicateSimilarity = float64(percent) / 100
+}
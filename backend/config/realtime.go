@@ -0,0 +1,27 @@
+package config
+
+import (
+	"context"
+	"log"
+
+	"quotes-app/realtime"
+)
+
+// Hub - хаб realtime-событий для SSE и WebSocket
+var Hub *realtime.Hub
+
+func InitRealtime() {
+	Hub = realtime.NewHub()
+
+	switch getEnv("REALTIME_BROKER", "memory") {
+	case "postgres":
+		// Несколько экземпляров приложения обмениваются событиями через LISTEN/NOTIFY
+		broker := realtime.NewPostgresBroker(DB, Hub, databaseDSN())
+		Hub.Broker = broker
+		go broker.Listen(context.Background())
+		log.Println("Realtime broker: postgres")
+	default:
+		Hub.Broker = realtime.NewMemoryBroker(Hub)
+		log.Println("Realtime broker: memory")
+	}
+}
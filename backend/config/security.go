@@ -0,0 +1,112 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotes-app/throttle"
+)
+
+var LoginGuard *throttle.LoginGuard
+
+var RateLimiter throttle.Limiter
+
+// RateLimits - лимиты по группам роутов. Переопределяются переменными окружения
+// RATE_LIMIT_<GROUP>=<количество>/<период>, например RATE_LIMIT_QUOTES=10/1m
+var RateLimits = map[string]throttle.Rate{
+	// Все запросы с одного IP
+	"global": {Limit: 600, Period: time.Minute},
+	// Регистрация, логин, обновление токенов, восстановление пароля
+	"auth": {Limit: 20, Period: time.Minute},
+	// Все запросы авторизованного пользователя
+	"user": {Limit: 300, Period: time.Minute},
+	// Создание цитат
+	"quotes": {Limit: 10, Period: time.Minute},
+	// Комментарии и ответы
+	"comments": {Limit: 20, Period: time.Minute},
+	// Лайки и дизлайки
+	"reactions": {Limit: 60, Period: time.Minute},
+}
+
+// InitLoginGuard настраивает защиту логина от перебора.
+// LOGIN_ATTEMPT_STORE=postgres нужен, когда запущено несколько реплик.
+func InitLoginGuard() {
+	lockout := time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute
+
+	accountPolicy := throttle.Policy{
+		FreeAttempts:    3,
+		MaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 10),
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: lockout,
+		Window:          lockout + time.Hour,
+	}
+	ipPolicy := throttle.Policy{
+		FreeAttempts:    20,
+		MaxFailures:     getEnvInt("LOGIN_IP_MAX_FAILURES", 100),
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: lockout,
+		Window:          lockout + time.Hour,
+	}
+
+	var store throttle.AttemptStore
+	switch getEnv("LOGIN_ATTEMPT_STORE", "memory") {
+	case "postgres":
+		store = throttle.NewPostgresAttemptStore(DB)
+		log.Println("Login attempts store: postgres")
+	default:
+		store = throttle.NewMemoryAttemptStore()
+		log.Println("Login attempts store: memory")
+	}
+
+	LoginGuard = throttle.NewLoginGuard(store, accountPolicy, ipPolicy)
+}
+
+// InitRateLimiter выбирает хранилище лимитера и применяет переопределения лимитов.
+// RATE_LIMIT_STORE=postgres нужен, когда запущено несколько реплик.
+func InitRateLimiter() {
+	for group, rate := range RateLimits {
+		value := os.Getenv("RATE_LIMIT_" + strings.ToUpper(group))
+		if value == "" {
+			continue
+		}
+		if parsed, ok := parseRate(value); ok {
+			RateLimits[group] = parsed
+		} else {
+			log.Printf("Invalid RATE_LIMIT_%s=%q, using %d/%s", strings.ToUpper(group), value, rate.Limit, rate.Period)
+		}
+	}
+
+	switch getEnv("RATE_LIMIT_STORE", "memory") {
+	case "postgres":
+		RateLimiter = throttle.NewPostgresLimiter(DB)
+		log.Println("Rate limit store: postgres")
+	default:
+		RateLimiter = throttle.NewMemoryLimiter()
+		log.Println("Rate limit store: memory")
+	}
+}
+
+// parseRate разбирает строку вида "10/1m"
+func parseRate(value string) (throttle.Rate, bool) {
+	count, period, found := strings.Cut(value, "/")
+	if !found {
+		return throttle.Rate{}, false
+	}
+
+	limit, err := strconv.Atoi(count)
+	if err != nil || limit <= 0 {
+		return throttle.Rate{}, false
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return throttle.Rate{}, false
+	}
+
+	return throttle.Rate{Limit: limit, Period: duration}, true
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"quotes-app/trash"
+)
+
+// TrashRetention - сколько удаленные цитаты и комментарии хранятся в корзине
+var TrashRetention = 30 * 24 * time.Hour
+
+func InitTrash() {
+	TrashRetention = time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+	interval := time.Duration(getEnvInt("TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute
+
+	go trash.Run(context.Background(), DB, TrashRetention, interval)
+}
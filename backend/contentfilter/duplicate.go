@@ -0,0 +1,98 @@
+package contentfilter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// DuplicateFilter ловит повторную отправку одного и того же текста: сравнивает его
+// с последними Limit записями пользователя того же типа за Window по триграммам.
+// Удаленные записи не учитываются (их можно отправить заново с исправлениями),
+// комментарии сравниваются только с комментариями к той же цитате.
+type DuplicateFilter struct {
+	DB     *gorm.DB
+	Window time.Duration
+	Limit  int
+	// Threshold - коэффициент Жаккара по триграммам, начиная с которого тексты считаются дублями
+	Threshold float64
+}
+
+func NewDuplicateFilter(db *gorm.DB, window time.Duration, limit int, threshold float64) *DuplicateFilter {
+	return &DuplicateFilter{DB: db, Window: window, Limit: limit, Threshold: threshold}
+}
+
+func (f *DuplicateFilter) Name() string { return "duplicates" }
+
+func (f *DuplicateFilter) Check(content Content) (*Match, error) {
+	if content.UserID == 0 {
+		return nil, nil
+	}
+
+	table := "quotes"
+	if content.Kind == KindComment {
+		table = "comments"
+	}
+
+	var recent []struct {
+		ID      uint
+		Content string
+	}
+	query := f.DB.Table(table).
+		Select("id", "content").
+		Where("user_id = ? AND created_at > ? AND id <> ? AND deleted_at IS NULL", content.UserID, time.Now().Add(-f.Window), content.ExcludeID)
+	if content.Kind == KindComment {
+		query = query.Where("quote_id = ?", content.QuoteID)
+	}
+	err := query.
+		Order("created_at DESC").
+		Limit(f.Limit).
+		Find(&recent).Error
+	if err != nil {
+		return nil, err
+	}
+
+	shingles := trigrams(content.Text)
+	for _, previous := range recent {
+		if similarity(shingles, trigrams(previous.Content)) >= f.Threshold {
+			return &Match{
+				Category: CategorySpam,
+				Reason:   "duplicates a recent " + content.Kind,
+				Terms:    []string{content.Kind + ":" + strconv.FormatUint(uint64(previous.ID), 10)},
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// trigrams - множество символьных триграмм текста без регистра, пунктуации и лишних пробелов
+func trigrams(text string) map[string]struct{} {
+	words := strings.FieldsFunc(normalizeWord(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	runes := []rune(" " + strings.Join(words, " ") + " ")
+
+	set := make(map[string]struct{}, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// similarity - коэффициент Жаккара двух множеств
+func similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	common := 0
+	for gram := range a {
+		if _, ok := b[gram]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
@@ -0,0 +1,33 @@
+package contentfilter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigramSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"identical", "Hello world", "Hello world", 1, 1},
+		{"case, yo and punctuation are ignored", "Ёлка, зелёная!", "елка   ЗЕЛЕНАЯ", 1, 1},
+		{"one word changed", "the quick brown fox jumps", "the quick brown cat jumps", 0.5, 0.9},
+		{"different texts", "to be or not to be", "все счастливые семьи похожи", 0, 0.05},
+		{"empty text", "", "something", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := similarity(trigrams(tt.a), trigrams(tt.b))
+			if got < tt.min-1e-9 || got > tt.max+1e-9 || math.IsNaN(got) {
+				t.Errorf("similarity(%q, %q) = %v, want in [%v, %v]", tt.a, tt.b, got, tt.min, tt.max)
+			}
+			if back := similarity(trigrams(tt.b), trigrams(tt.a)); back != got {
+				t.Errorf("similarity is not symmetric: %v != %v", got, back)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package contentfilter проверяет пользовательский текст перед сохранением:
+// запрещенные слова, спам ссылками и повторы. Каждый фильтр настраивается
+// на одно действие - отклонить, замаскировать или отправить на модерацию.
+package contentfilter
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action - что делать с текстом, который зацепил фильтр
+type Action string
+
+const (
+	ActionAllow  Action = "allow"
+	ActionMask   Action = "mask"
+	ActionQueue  Action = "queue"
+	ActionReject Action = "reject"
+)
+
+// severity - при нескольких срабатываниях итоговым становится самое строгое действие
+var severity = map[Action]int{ActionAllow: 0, ActionMask: 1, ActionQueue: 2, ActionReject: 3}
+
+// ParseAction читает действие из конфигурации; "off" отключает фильтр
+func ParseAction(value string) (Action, bool, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(value))); action {
+	case "off":
+		return "", false, nil
+	case ActionMask, ActionQueue, ActionReject:
+		return action, true, nil
+	default:
+		return "", false, fmt.Errorf("unknown content filter action %q", value)
+	}
+}
+
+// Типы проверяемого контента
+const (
+	KindQuote   = "quote"
+	KindComment = "comment"
+)
+
+// Content - проверяемый текст и его контекст
+type Content struct {
+	Kind   string
+	Text   string
+	UserID uint
+	// ExcludeID - при редактировании сам объект не считается своим дубликатом
+	ExcludeID uint
+	// QuoteID - для комментариев: повтором считается только комментарий к той же цитате
+	QuoteID uint
+}
+
+// Категории нарушений (совпадают с причинами жалоб)
+const (
+	CategoryOffensive = "offensive"
+	CategorySpam      = "spam"
+)
+
+// Span - байтовый диапазон совпадения в тексте
+type Span struct {
+	Start int
+	End   int
+}
+
+// Match - срабатывание фильтра
+type Match struct {
+	Category string
+	Reason   string
+	Terms    []string
+	// Spans - что закрыть звездочками; без них маскировать нечего и текст уходит на модерацию
+	Spans []Span
+}
+
+// ContentFilter - одна проверка текста; nil Match означает, что текст чистый
+type ContentFilter interface {
+	Name() string
+	Check(content Content) (*Match, error)
+}
+
+// Rule - фильтр и действие при его срабатывании
+type Rule struct {
+	Filter ContentFilter
+	Action Action
+}
+
+// Decision - решение одного фильтра для журнала
+type Decision struct {
+	Filter   string
+	Action   Action
+	Category string
+	Reason   string
+	Terms    []string
+}
+
+// Result - итог проверки: текст после маскирования и решения сработавших фильтров
+type Result struct {
+	Text      string
+	Action    Action
+	Decisions []Decision
+}
+
+func (r Result) Rejected() bool { return r.Action == ActionReject }
+
+func (r Result) Queued() bool { return r.Action == ActionQueue }
+
+// Rejection - решение, из-за которого текст отклонен
+func (r Result) Rejection() *Decision {
+	for i := range r.Decisions {
+		if r.Decisions[i].Action == ActionReject {
+			return &r.Decisions[i]
+		}
+	}
+	return nil
+}
+
+// Pipeline применяет правила по порядку
+type Pipeline struct {
+	Rules []Rule
+}
+
+// Apply проверяет текст. Первое отклонение останавливает проверку, маскирование
+// применяется сразу, и следующие фильтры видят уже замаскированный текст.
+// Ошибка фильтра (например, БД недоступна) только логируется. Безопасен для nil.
+func (p *Pipeline) Apply(content Content) Result {
+	result := Result{Text: content.Text, Action: ActionAllow}
+	if p == nil {
+		return result
+	}
+
+	for _, rule := range p.Rules {
+		content.Text = result.Text
+		match, err := rule.Filter.Check(content)
+		if err != nil {
+			log.Printf("Content filter %s failed: %v", rule.Filter.Name(), err)
+			continue
+		}
+		if match == nil {
+			continue
+		}
+
+		action := rule.Action
+		if action == ActionMask {
+			if len(match.Spans) == 0 {
+				action = ActionQueue
+			} else {
+				result.Text = maskSpans(result.Text, match.Spans)
+			}
+		}
+
+		result.Decisions = append(result.Decisions, Decision{
+			Filter:   rule.Filter.Name(),
+			Action:   action,
+			Category: match.Category,
+			Reason:   match.Reason,
+			Terms:    match.Terms,
+		})
+		if severity[action] > severity[result.Action] {
+			result.Action = action
+		}
+		if action == ActionReject {
+			break
+		}
+	}
+
+	return result
+}
+
+// maskSpans заменяет каждый символ совпадений на '*', сохраняя длину текста в символах
+func maskSpans(text string, spans []Span) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var b strings.Builder
+	pos := 0
+	for _, span := range spans {
+		if span.Start < pos {
+			span.Start = pos
+		}
+		if span.End <= span.Start {
+			continue
+		}
+		b.WriteString(text[pos:span.Start])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[span.Start:span.End])))
+		pos = span.End
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
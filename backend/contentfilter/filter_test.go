@@ -0,0 +1,223 @@
+package contentfilter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMaskSpans(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		spans []Span
+		want  string
+	}{
+		{"no spans", "hello", nil, "hello"},
+		{"single span", "hello world", []Span{{6, 11}}, "hello *****"},
+		{"unsorted spans", "ab cd ef", []Span{{6, 8}, {0, 2}}, "** cd **"},
+		{"adjacent spans", "abcdef", []Span{{0, 3}, {3, 6}}, "******"},
+		{"overlapping spans", "abcdefgh", []Span{{1, 5}, {3, 7}}, "a******h"},
+		{"nested span", "abcdefgh", []Span{{1, 7}, {2, 4}}, "a******h"},
+		{"empty span", "abc", []Span{{1, 1}}, "abc"},
+		// Кириллица занимает два байта на символ, звездочек - по одной на символ
+		{"multibyte", "ну блин же", []Span{{5, 13}}, "ну **** же"},
+		{"multibyte overlapping", "ёжик", []Span{{0, 4}, {2, 8}}, "****"},
+		{"emoji", "ok 🙂 ok", []Span{{3, 7}}, "ok * ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskSpans(tt.text, tt.spans); got != tt.want {
+				t.Errorf("maskSpans(%q, %v) = %q, want %q", tt.text, tt.spans, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Action
+		enabled bool
+		wantErr bool
+	}{
+		{"mask", ActionMask, true, false},
+		{" Queue ", ActionQueue, true, false},
+		{"REJECT", ActionReject, true, false},
+		{"off", "", false, false},
+		{"allow", "", false, true},
+		{"", "", false, true},
+	}
+
+	for _, tt := range tests {
+		got, enabled, err := ParseAction(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAction(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want || enabled != tt.enabled {
+			t.Errorf("ParseAction(%q) = %q, %v, want %q, %v", tt.value, got, enabled, tt.want, tt.enabled)
+		}
+	}
+}
+
+// stubFilter срабатывает на весь текст, если в нем есть word
+type stubFilter struct {
+	name    string
+	word    string
+	noSpans bool
+	err     error
+	seen    *[]string
+}
+
+func (f stubFilter) Name() string { return f.name }
+
+func (f stubFilter) Check(content Content) (*Match, error) {
+	if f.seen != nil {
+		*f.seen = append(*f.seen, content.Text)
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	start := strings.Index(content.Text, f.word)
+	if start < 0 {
+		return nil, nil
+	}
+	match := &Match{Category: CategorySpam, Reason: f.name, Terms: []string{f.word}}
+	if !f.noSpans {
+		match.Spans = []Span{{start, start + len(f.word)}}
+	}
+	return match, nil
+}
+
+func TestPipelineApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []Rule
+		text      string
+		wantText  string
+		wantAct   Action
+		decisions []string
+	}{
+		{
+			name:     "no match",
+			rules:    []Rule{{stubFilter{name: "a", word: "bad"}, ActionReject}},
+			text:     "good",
+			wantText: "good",
+			wantAct:  ActionAllow,
+		},
+		{
+			name:      "mask",
+			rules:     []Rule{{stubFilter{name: "a", word: "bad"}, ActionMask}},
+			text:      "so bad",
+			wantText:  "so ***",
+			wantAct:   ActionMask,
+			decisions: []string{"a"},
+		},
+		{
+			name:      "mask without spans escalates to queue",
+			rules:     []Rule{{stubFilter{name: "a", word: "bad", noSpans: true}, ActionMask}},
+			text:      "so bad",
+			wantText:  "so bad",
+			wantAct:   ActionQueue,
+			decisions: []string{"a"},
+		},
+		{
+			name: "strictest action wins",
+			rules: []Rule{
+				{stubFilter{name: "a", word: "spam"}, ActionQueue},
+				{stubFilter{name: "b", word: "bad"}, ActionMask},
+			},
+			text:      "bad spam",
+			wantText:  "*** spam",
+			wantAct:   ActionQueue,
+			decisions: []string{"a", "b"},
+		},
+		{
+			name: "reject stops the pipeline",
+			rules: []Rule{
+				{stubFilter{name: "a", word: "bad"}, ActionReject},
+				{stubFilter{name: "b", word: "bad"}, ActionMask},
+			},
+			text:      "bad",
+			wantText:  "bad",
+			wantAct:   ActionReject,
+			decisions: []string{"a"},
+		},
+		{
+			name: "masked text is not matched again",
+			rules: []Rule{
+				{stubFilter{name: "a", word: "bad"}, ActionMask},
+				{stubFilter{name: "b", word: "bad"}, ActionReject},
+			},
+			text:      "bad",
+			wantText:  "***",
+			wantAct:   ActionMask,
+			decisions: []string{"a"},
+		},
+		{
+			name: "failing filter is skipped",
+			rules: []Rule{
+				{stubFilter{name: "a", err: errors.New("db is down")}, ActionReject},
+				{stubFilter{name: "b", word: "bad"}, ActionMask},
+			},
+			text:      "bad",
+			wantText:  "***",
+			wantAct:   ActionMask,
+			decisions: []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Pipeline{Rules: tt.rules}
+			result := p.Apply(Content{Kind: KindQuote, Text: tt.text})
+
+			if result.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", result.Text, tt.wantText)
+			}
+			if result.Action != tt.wantAct {
+				t.Errorf("Action = %q, want %q", result.Action, tt.wantAct)
+			}
+			if len(result.Decisions) != len(tt.decisions) {
+				t.Fatalf("Decisions = %+v, want filters %v", result.Decisions, tt.decisions)
+			}
+			for i, name := range tt.decisions {
+				if result.Decisions[i].Filter != name {
+					t.Errorf("Decisions[%d].Filter = %q, want %q", i, result.Decisions[i].Filter, name)
+				}
+			}
+			if result.Rejected() != (tt.wantAct == ActionReject) {
+				t.Errorf("Rejected() = %v", result.Rejected())
+			}
+			if result.Queued() != (tt.wantAct == ActionQueue) {
+				t.Errorf("Queued() = %v", result.Queued())
+			}
+			if (result.Rejection() != nil) != result.Rejected() {
+				t.Errorf("Rejection() = %+v, Rejected() = %v", result.Rejection(), result.Rejected())
+			}
+		})
+	}
+}
+
+func TestPipelineApplyPassesMaskedText(t *testing.T) {
+	var seen []string
+	p := &Pipeline{Rules: []Rule{
+		{stubFilter{name: "a", word: "bad"}, ActionMask},
+		{stubFilter{name: "b", word: "none", seen: &seen}, ActionReject},
+	}}
+	p.Apply(Content{Text: "bad"})
+
+	if len(seen) != 1 || seen[0] != "***" {
+		t.Errorf("second filter saw %q, want [\"***\"]", seen)
+	}
+}
+
+func TestNilPipeline(t *testing.T) {
+	var p *Pipeline
+	result := p.Apply(Content{Text: "anything"})
+	if result.Text != "anything" || result.Action != ActionAllow || len(result.Decisions) != 0 {
+		t.Errorf("nil Pipeline.Apply() = %+v", result)
+	}
+}
@@ -0,0 +1,75 @@
+package contentfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// linkRe - ссылки со схемой, с www. и голые домены в популярных зонах
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|net|org|ru|su|io|info|biz|xyz|top|site|online|club|shop|ly|me|co)\b(?:/[^\s<>"']*)?`)
+
+// LinkFilter срабатывает, если ссылок больше MaxLinks или есть ссылка на запрещенный домен
+type LinkFilter struct {
+	MaxLinks       int
+	BlockedDomains []string
+}
+
+func NewLinkFilter(maxLinks int, blockedDomains []string) *LinkFilter {
+	f := &LinkFilter{MaxLinks: maxLinks}
+	for _, domain := range blockedDomains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			f.BlockedDomains = append(f.BlockedDomains, domain)
+		}
+	}
+	return f
+}
+
+func (f *LinkFilter) Name() string { return "links" }
+
+func (f *LinkFilter) Check(content Content) (*Match, error) {
+	locs := linkRe.FindAllStringIndex(content.Text, -1)
+	if len(locs) == 0 {
+		return nil, nil
+	}
+
+	match := &Match{Category: CategorySpam}
+	for _, loc := range locs {
+		link := content.Text[loc[0]:loc[1]]
+		match.Terms = append(match.Terms, link)
+		match.Spans = append(match.Spans, Span{Start: loc[0], End: loc[1]})
+		if match.Reason == "" && f.blocked(linkHost(link)) {
+			match.Reason = "links to a blocked domain"
+		}
+	}
+
+	if match.Reason == "" && len(locs) > f.MaxLinks {
+		match.Reason = fmt.Sprintf("contains more than %d links", f.MaxLinks)
+	}
+	if match.Reason == "" {
+		return nil, nil
+	}
+	return match, nil
+}
+
+// blocked - домен или его поддомен в списке запрещенных
+func (f *LinkFilter) blocked(host string) bool {
+	for _, domain := range f.BlockedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkHost - домен ссылки без схемы, www., порта и пути
+func linkHost(link string) string {
+	host := strings.ToLower(link)
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#:"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
@@ -0,0 +1,87 @@
+package contentfilter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLinkRe(t *testing.T) {
+	tests := []struct {
+		text  string
+		links []string
+	}{
+		{"no links here", nil},
+		{"see https://example.com/page?x=1 now", []string{"https://example.com/page?x=1"}},
+		{"HTTP://EXAMPLE.COM", []string{"HTTP://EXAMPLE.COM"}},
+		{"go to www.example.org", []string{"www.example.org"}},
+		{"visit casino.xyz/win today", []string{"casino.xyz/win"}},
+		{"shop at my-store.shop.", []string{"my-store.shop"}},
+		{"sub.domain.ru and bit.ly/abc", []string{"sub.domain.ru", "bit.ly/abc"}},
+		{"<a href=\"http://spam.io\">", []string{"http://spam.io"}},
+		{"file.txt and version 1.2", nil},
+		{"end of sentence.Next one", nil},
+	}
+
+	for _, tt := range tests {
+		if got := linkRe.FindAllString(tt.text, -1); !slices.Equal(got, tt.links) {
+			t.Errorf("links in %q = %q, want %q", tt.text, got, tt.links)
+		}
+	}
+}
+
+func TestLinkHost(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://Example.com/path", "example.com"},
+		{"http://www.example.com:8080/", "example.com"},
+		{"www.example.org?q=1", "example.org"},
+		{"bit.ly/abc", "bit.ly"},
+		{"sub.domain.ru#top", "sub.domain.ru"},
+	}
+
+	for _, tt := range tests {
+		if got := linkHost(tt.link); got != tt.want {
+			t.Errorf("linkHost(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	f := NewLinkFilter(2, []string{" Casino.com ", ""})
+
+	tests := []struct {
+		name   string
+		text   string
+		reason string
+	}{
+		{"no links", "just text", ""},
+		{"links within limit", "a.com and b.org", ""},
+		{"too many links", "a.com b.org c.net", "contains more than 2 links"},
+		{"blocked domain", "play at casino.com", "links to a blocked domain"},
+		{"blocked subdomain", "https://www.win.casino.com/x", "links to a blocked domain"},
+		{"similar domain is not blocked", "notcasino.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := f.Check(Content{Text: tt.text})
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.reason == "" {
+				if match != nil {
+					t.Fatalf("Check() = %+v, want no match", match)
+				}
+				return
+			}
+			if match == nil || match.Reason != tt.reason {
+				t.Fatalf("Check() = %+v, want reason %q", match, tt.reason)
+			}
+			if match.Category != CategorySpam || len(match.Spans) != len(match.Terms) {
+				t.Errorf("Check() = %+v", match)
+			}
+		})
+	}
+}
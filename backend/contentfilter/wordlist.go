@@ -0,0 +1,103 @@
+package contentfilter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Встроенные списки. Формат записи: "слово" - точное совпадение, "слово*" - начало слова,
+// "*слово*" - часть слова. Подстроки используются только для корней, которые не встречаются
+// в обычных словах, иначе фильтр начнет цеплять "class" или "хлебало". Слова с обычным
+// значением ("Moby Dick", французское "retard", "prick", британское "fag") в список не входят:
+// в цитатах они чаще встречаются в приличном смысле.
+var (
+	englishWords = []string{
+		"*fuck*", "shit", "shits", "shitty", "bullshit", "bitch*", "cunt*", "asshole*",
+		"dickhead*", "bastard*", "whore*", "slut*", "faggot*",
+		"nigger*", "nigga*", "retarded", "twat*", "wanker*", "cocksucker*",
+		"motherf*", "douchebag*",
+	}
+	russianWords = []string{
+		"*пизд*", "хуй*", "хуе*", "хуя*", "хуи*", "нахуй", "похуй*", "нихуя",
+		"еба*", "ебу*", "ебл*", "заеб*", "наеб*", "уеб*", "выеб*", "отъеб*", "съеб*", "доеб*", "долбоеб*",
+		"бля", "блять", "бляд*", "сука", "суки", "сучар*", "мудак*", "мудил*", "гандон*",
+		"пидор*", "пидар*", "залуп*", "шлюх*",
+	}
+)
+
+// wordRe - слова текста: буквы и цифры любых алфавитов
+var wordRe = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+type wordPattern struct {
+	text     string
+	prefix   bool
+	contains bool
+}
+
+func (p wordPattern) matches(word string) bool {
+	switch {
+	case p.contains:
+		return strings.Contains(word, p.text)
+	case p.prefix:
+		return strings.HasPrefix(word, p.text)
+	default:
+		return word == p.text
+	}
+}
+
+// WordlistFilter ищет запрещенные слова без учета регистра и различия е/ё
+type WordlistFilter struct {
+	patterns []wordPattern
+}
+
+// NewWordlistFilter собирает фильтр из встроенных русского и английского списков и extra
+func NewWordlistFilter(extra []string) *WordlistFilter {
+	f := &WordlistFilter{}
+	for _, list := range [][]string{englishWords, russianWords, extra} {
+		for _, entry := range list {
+			if pattern, ok := parseWordPattern(entry); ok {
+				f.patterns = append(f.patterns, pattern)
+			}
+		}
+	}
+	return f
+}
+
+func parseWordPattern(entry string) (wordPattern, bool) {
+	entry = normalizeWord(strings.TrimSpace(entry))
+	pattern := wordPattern{}
+	if strings.HasPrefix(entry, "*") && strings.HasSuffix(entry, "*") && len(entry) > 2 {
+		pattern.contains = true
+		entry = strings.Trim(entry, "*")
+	} else if strings.HasSuffix(entry, "*") {
+		pattern.prefix = true
+		entry = strings.TrimSuffix(entry, "*")
+	}
+	pattern.text = entry
+	return pattern, entry != "" && !strings.Contains(entry, "*")
+}
+
+func normalizeWord(word string) string {
+	return strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+}
+
+func (f *WordlistFilter) Name() string { return "wordlist" }
+
+func (f *WordlistFilter) Check(content Content) (*Match, error) {
+	var match *Match
+	for _, loc := range wordRe.FindAllStringIndex(content.Text, -1) {
+		word := normalizeWord(content.Text[loc[0]:loc[1]])
+		for _, pattern := range f.patterns {
+			if !pattern.matches(word) {
+				continue
+			}
+			if match == nil {
+				match = &Match{Category: CategoryOffensive, Reason: "contains prohibited words"}
+			}
+			match.Terms = append(match.Terms, word)
+			match.Spans = append(match.Spans, Span{Start: loc[0], End: loc[1]})
+			break
+		}
+	}
+	return match, nil
+}
@@ -0,0 +1,87 @@
+package contentfilter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWordlistFilter(t *testing.T) {
+	f := NewWordlistFilter([]string{"спам*", "*казино*", "exact"})
+
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+	}{
+		{"clean text", "Жизнь прекрасна", nil},
+		{"exact word", "what the shit", []string{"shit"}},
+		{"exact word does not match longer word", "shitake mushrooms", nil},
+		{"prefix", "Ты бля дь", []string{"бля"}},
+		{"prefix matches longer word", "bitches be bitching", []string{"bitches", "bitching"}},
+		{"prefix does not match inside word", "snowbitch", nil},
+		{"contains", "motherfucking mess", []string{"motherfucking"}},
+		{"case and yo are ignored", "ЗаЁбанный день", []string{"заебанный"}},
+		{"extra prefix", "Спамеры повсюду", []string{"спамеры"}},
+		{"extra contains", "онлайнказино рядом", []string{"онлайнказино"}},
+		{"extra exact", "Exact, not exactly", []string{"exact"}},
+		{"substring roots do not hit ordinary words", "class хлебало assistant", nil},
+		{"proper name", "Moby Dick by Herman Melville", nil},
+		{"french word", "Le train est en retard", nil},
+		{"ordinary verb", "a prick of conscience", nil},
+		{"cigarette", "fancy a fag?", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := f.Check(Content{Kind: KindQuote, Text: tt.text})
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.terms == nil {
+				if match != nil {
+					t.Fatalf("Check() = %v, want no match", match.Terms)
+				}
+				return
+			}
+			if match == nil {
+				t.Fatalf("Check() = nil, want %v", tt.terms)
+			}
+			if !slices.Equal(match.Terms, tt.terms) {
+				t.Errorf("Terms = %v, want %v", match.Terms, tt.terms)
+			}
+			if len(match.Spans) != len(tt.terms) {
+				t.Errorf("Spans = %v, want %d spans", match.Spans, len(tt.terms))
+			}
+			if match.Category != CategoryOffensive {
+				t.Errorf("Category = %q, want %q", match.Category, CategoryOffensive)
+			}
+		})
+	}
+}
+
+func TestParseWordPattern(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  wordPattern
+		ok    bool
+	}{
+		{"word", wordPattern{text: "word"}, true},
+		{" Word* ", wordPattern{text: "word", prefix: true}, true},
+		{"*ёж*", wordPattern{text: "еж", contains: true}, true},
+		{"*", wordPattern{}, false},
+		{"**", wordPattern{prefix: true, text: "*"}, false},
+		{"wo*rd", wordPattern{text: "wo*rd"}, false},
+		{"", wordPattern{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseWordPattern(tt.entry)
+		if ok != tt.ok {
+			t.Errorf("parseWordPattern(%q) ok = %v, want %v", tt.entry, ok, tt.ok)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("parseWordPattern(%q) = %+v, want %+v", tt.entry, got, tt.want)
+		}
+	}
+}
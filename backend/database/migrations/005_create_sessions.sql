@@ -0,0 +1,18 @@
+-- Сессии пользователей (refresh токены)
+CREATE TABLE IF NOT EXISTS sessions (
+                                        id SERIAL PRIMARY KEY,
+                                        user_id INTEGER NOT NULL,
+                                        refresh_token_hash VARCHAR(64) UNIQUE NOT NULL,
+                                        previous_token_hash VARCHAR(64),
+                                        user_agent VARCHAR(255),
+                                        ip_address VARCHAR(45),
+                                        expires_at TIMESTAMP NOT NULL,
+                                        revoked_at TIMESTAMP,
+                                        last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_token_hash ON sessions(previous_token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
@@ -0,0 +1,22 @@
+-- Роли пользователей: user, moderator, admin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'role') THEN
+        ALTER TABLE users
+            ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+        -- Тестовый пользователь admin получает права администратора один раз,
+        -- повторный запуск миграции не возвращает снятую роль
+        UPDATE users SET role = 'admin' WHERE username = 'admin';
+    END IF;
+END $$;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS check_user_role;
+
+ALTER TABLE users
+    ADD CONSTRAINT check_user_role
+        CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
@@ -0,0 +1,17 @@
+-- Полнотекстовый поиск по цитатам (русская и английская конфигурации)
+ALTER TABLE quotes
+    ADD COLUMN IF NOT EXISTS search_vector_ru tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('russian', coalesce(content, '')), 'A') ||
+            setweight(to_tsvector('russian', coalesce(author, '')), 'B')
+        ) STORED;
+
+ALTER TABLE quotes
+    ADD COLUMN IF NOT EXISTS search_vector_en tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('english', coalesce(content, '')), 'A') ||
+            setweight(to_tsvector('english', coalesce(author, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_quotes_search_vector_ru ON quotes USING GIN (search_vector_ru);
+CREATE INDEX IF NOT EXISTS idx_quotes_search_vector_en ON quotes USING GIN (search_vector_en);
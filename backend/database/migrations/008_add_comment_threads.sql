@@ -0,0 +1,9 @@
+-- Ветки комментариев: ответы ссылаются на родительский комментарий
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+
+-- Удаленный комментарий с ответами остается заглушкой "[deleted]"
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id);
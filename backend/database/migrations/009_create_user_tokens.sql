@@ -0,0 +1,13 @@
+-- Одноразовые токены пользователей (сброс пароля и т.п.), храним только хеши
+CREATE TABLE IF NOT EXISTS user_tokens (
+                                           id SERIAL PRIMARY KEY,
+                                           user_id INTEGER NOT NULL,
+                                           purpose VARCHAR(30) NOT NULL,
+                                           token_hash VARCHAR(64) UNIQUE NOT NULL,
+                                           expires_at TIMESTAMP NOT NULL,
+                                           used_at TIMESTAMP,
+                                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                           FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
@@ -0,0 +1,14 @@
+-- Подтверждение email.
+-- Колонка и заполнение выполняются один раз: при повторном запуске миграции
+-- неподтвержденные аккаунты не должны становиться подтвержденными
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'email_verified_at') THEN
+        ALTER TABLE users
+            ADD COLUMN email_verified_at TIMESTAMP;
+
+        -- Уже существующие аккаунты (в том числе тестовый admin) считаем подтвержденными
+        UPDATE users SET email_verified_at = created_at;
+    END IF;
+END $$;
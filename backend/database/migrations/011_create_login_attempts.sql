@@ -0,0 +1,8 @@
+-- Счетчики неудачных попыток входа (по аккаунту и по IP) для хранилища postgres
+CREATE TABLE IF NOT EXISTS login_attempts (
+                                              attempt_key VARCHAR(255) PRIMARY KEY,
+                                              failures INTEGER NOT NULL DEFAULT 0,
+                                              last_failure_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
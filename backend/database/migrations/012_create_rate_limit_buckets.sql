@@ -0,0 +1,9 @@
+-- Бакеты token bucket для rate limiting (хранилище postgres, общее для реплик)
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+                                                  bucket_key VARCHAR(255) PRIMARY KEY,
+                                                  tokens DOUBLE PRECISION NOT NULL,
+                                                  allowed BOOLEAN NOT NULL DEFAULT TRUE,
+                                                  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
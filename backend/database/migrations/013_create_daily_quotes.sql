@@ -0,0 +1,14 @@
+-- История цитат дня: одна запись на день для всей ленты (category_id IS NULL) и для каждой категории
+CREATE TABLE IF NOT EXISTS daily_quotes (
+                                            id SERIAL PRIMARY KEY,
+                                            quote_date DATE NOT NULL,
+                                            category_id INTEGER,
+                                            quote_id INTEGER NOT NULL,
+                                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                            FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+                                            FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE CASCADE
+);
+
+-- NULL в category_id не участвует в уникальности, поэтому индексируем COALESCE
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_quotes_date_scope ON daily_quotes(quote_date, (COALESCE(category_id, 0)));
+CREATE INDEX IF NOT EXISTS idx_daily_quotes_scope_date ON daily_quotes((COALESCE(category_id, 0)), quote_date DESC);
@@ -0,0 +1,20 @@
+-- Теги цитат (many-to-many в дополнение к категории)
+CREATE TABLE IF NOT EXISTS tags (
+                                    id SERIAL PRIMARY KEY,
+                                    name VARCHAR(50) NOT NULL,
+                                    slug VARCHAR(50) UNIQUE NOT NULL,
+                                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS quote_tags (
+                                          quote_id INTEGER NOT NULL,
+                                          tag_id INTEGER NOT NULL,
+                                          PRIMARY KEY (quote_id, tag_id),
+                                          FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE CASCADE,
+                                          FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_tags_tag_id ON quote_tags(tag_id);
+
+-- Автодополнение по префиксу (slug LIKE 'prefix%')
+CREATE INDEX IF NOT EXISTS idx_tags_slug_prefix ON tags(slug varchar_pattern_ops);
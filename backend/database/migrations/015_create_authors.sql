@@ -0,0 +1,46 @@
+-- Авторы цитат: каноническое имя, псевдонимы, краткая биография и годы жизни.
+-- name_key - нормализованное имя (нижний регистр, без точек и запятых, одиночные пробелы),
+-- по нему сопоставляются имена авторов и псевдонимов
+CREATE TABLE IF NOT EXISTS authors (
+                                       id SERIAL PRIMARY KEY,
+                                       name VARCHAR(100) NOT NULL,
+                                       name_key VARCHAR(100) UNIQUE NOT NULL,
+                                       bio TEXT,
+                                       birth_year INTEGER,
+                                       death_year INTEGER,
+                                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                       updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                       CONSTRAINT check_author_years CHECK (death_year IS NULL OR birth_year IS NULL OR death_year >= birth_year)
+);
+
+CREATE TABLE IF NOT EXISTS author_aliases (
+                                              id SERIAL PRIMARY KEY,
+                                              author_id INTEGER NOT NULL,
+                                              name VARCHAR(100) NOT NULL,
+                                              name_key VARCHAR(100) UNIQUE NOT NULL,
+                                              FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_author_aliases_author_id ON author_aliases(author_id);
+
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS author_id INTEGER REFERENCES authors(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_quotes_author_id ON quotes(author_id);
+
+-- Переносим существующие строки авторов: одно имя (с точностью до name_key) - один автор
+INSERT INTO authors (name, name_key)
+SELECT DISTINCT ON (name_key) name, name_key
+FROM (
+         SELECT TRIM(author) AS name,
+                LOWER(TRIM(REGEXP_REPLACE(REGEXP_REPLACE(author, '[.,]', ' ', 'g'), '\s+', ' ', 'g'))) AS name_key
+         FROM quotes
+         WHERE author IS NOT NULL AND TRIM(author) <> ''
+     ) AS names
+WHERE name_key <> ''
+ORDER BY name_key, name
+ON CONFLICT (name_key) DO NOTHING;
+
+UPDATE quotes
+SET author_id = authors.id
+FROM authors
+WHERE quotes.author_id IS NULL
+  AND authors.name_key = LOWER(TRIM(REGEXP_REPLACE(REGEXP_REPLACE(quotes.author, '[.,]', ' ', 'g'), '\s+', ' ', 'g')));
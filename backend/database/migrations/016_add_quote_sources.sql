@@ -0,0 +1,10 @@
+-- Источник цитаты: произведение, год, страница, ссылка и тип источника
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS source_type VARCHAR(20);
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS source_title VARCHAR(255);
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS source_year INTEGER;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS source_page VARCHAR(20);
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS source_url VARCHAR(500);
+
+ALTER TABLE quotes DROP CONSTRAINT IF EXISTS check_quote_source_type;
+ALTER TABLE quotes ADD CONSTRAINT check_quote_source_type
+    CHECK (source_type IS NULL OR source_type IN ('book', 'speech', 'article', 'interview', 'letter', 'film', 'website', 'other'));
@@ -0,0 +1,29 @@
+-- Коллекции цитат пользователей (избранное, подборки)
+CREATE TABLE IF NOT EXISTS collections (
+                                           id SERIAL PRIMARY KEY,
+                                           user_id INTEGER NOT NULL,
+                                           name VARCHAR(100) NOT NULL,
+                                           description TEXT,
+                                           visibility VARCHAR(10) NOT NULL DEFAULT 'private',
+                                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                           updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                           FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+                                           CONSTRAINT check_collection_visibility CHECK (visibility IN ('private', 'public'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_collections_user_id ON collections(user_id);
+
+-- Цитаты в коллекции; position задает пользовательский порядок.
+-- При удалении цитаты она пропадает из всех коллекций
+CREATE TABLE IF NOT EXISTS collection_quotes (
+                                                 collection_id INTEGER NOT NULL,
+                                                 quote_id INTEGER NOT NULL,
+                                                 position INTEGER NOT NULL,
+                                                 added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                 PRIMARY KEY (collection_id, quote_id),
+                                                 FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE,
+                                                 FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_quotes_position ON collection_quotes(collection_id, position);
+CREATE INDEX IF NOT EXISTS idx_collection_quotes_quote_id ON collection_quotes(quote_id);
@@ -0,0 +1,26 @@
+-- Подписки на пользователей и категории для персональной ленты
+CREATE TABLE IF NOT EXISTS user_follows (
+                                            follower_id INTEGER NOT NULL,
+                                            followee_id INTEGER NOT NULL,
+                                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                            PRIMARY KEY (follower_id, followee_id),
+                                            FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+                                            FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE,
+                                            CONSTRAINT check_user_follows_self CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows(followee_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS category_follows (
+                                                user_id INTEGER NOT NULL,
+                                                category_id INTEGER NOT NULL,
+                                                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                PRIMARY KEY (user_id, category_id),
+                                                FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+                                                FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+-- Лента читает последние цитаты каждого источника отдельно (LATERAL + LIMIT),
+-- поэтому нужны индексы по автору/категории с порядком ленты
+CREATE INDEX IF NOT EXISTS idx_quotes_user_feed ON quotes(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_quotes_category_feed ON quotes(category_id, created_at DESC, id DESC);
@@ -0,0 +1,44 @@
+-- Уведомления о лайках, комментариях и ответах.
+-- Однотипные события по одному объекту агрегируются в одно непрочитанное уведомление
+-- (group_key), actor_count - число разных пользователей
+CREATE TABLE IF NOT EXISTS notifications (
+                                             id SERIAL PRIMARY KEY,
+                                             user_id INTEGER NOT NULL,
+                                             type VARCHAR(30) NOT NULL,
+                                             group_key VARCHAR(100) NOT NULL,
+                                             quote_id INTEGER,
+                                             comment_id INTEGER,
+                                             last_actor_id INTEGER,
+                                             actor_count INTEGER NOT NULL DEFAULT 0,
+                                             read_at TIMESTAMP,
+                                             created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                             updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                             FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+                                             FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE CASCADE,
+                                             FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE SET NULL,
+                                             FOREIGN KEY (last_actor_id) REFERENCES users(id) ON DELETE SET NULL,
+                                             CONSTRAINT check_notification_type CHECK (type IN ('quote_like', 'quote_comment', 'comment_reply', 'comment_like'))
+);
+
+-- Одно непрочитанное уведомление на группу; после прочтения новые события создают новое
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_unread_group ON notifications(user_id, group_key) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_updated ON notifications(user_id, updated_at DESC);
+
+-- Кто уже учтен в уведомлении (повторный лайк того же пользователя не увеличивает счетчик)
+CREATE TABLE IF NOT EXISTS notification_actors (
+                                                   notification_id INTEGER NOT NULL,
+                                                   actor_id INTEGER NOT NULL,
+                                                   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                   PRIMARY KEY (notification_id, actor_id),
+                                                   FOREIGN KEY (notification_id) REFERENCES notifications(id) ON DELETE CASCADE,
+                                                   FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Отключенные типы уведомлений; отсутствие строки означает, что тип включен
+CREATE TABLE IF NOT EXISTS notification_preferences (
+                                                        user_id INTEGER NOT NULL,
+                                                        type VARCHAR(30) NOT NULL,
+                                                        enabled BOOLEAN NOT NULL DEFAULT TRUE,
+                                                        PRIMARY KEY (user_id, type),
+                                                        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
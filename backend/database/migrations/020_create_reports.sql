@@ -0,0 +1,38 @@
+-- Жалобы пользователей на цитаты и комментарии.
+-- Жалоба относится ровно к одному объекту: quote_id для цитат, comment_id для комментариев
+CREATE TABLE IF NOT EXISTS reports (
+                                       id SERIAL PRIMARY KEY,
+                                       reporter_id INTEGER,
+                                       target_type VARCHAR(10) NOT NULL,
+                                       quote_id INTEGER,
+                                       comment_id INTEGER,
+                                       reason VARCHAR(20) NOT NULL,
+                                       details TEXT,
+                                       status VARCHAR(10) NOT NULL DEFAULT 'pending',
+                                       resolved_by INTEGER,
+                                       resolved_at TIMESTAMP,
+                                       resolution_note TEXT,
+                                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                       FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE SET NULL,
+                                       FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE CASCADE,
+                                       FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+                                       FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL,
+                                       CONSTRAINT check_report_target CHECK (
+                                           (target_type = 'quote' AND quote_id IS NOT NULL AND comment_id IS NULL) OR
+                                           (target_type = 'comment' AND comment_id IS NOT NULL AND quote_id IS NULL)
+                                           ),
+                                       CONSTRAINT check_report_reason CHECK (reason IN ('spam', 'offensive', 'harassment', 'misinformation', 'copyright', 'other')),
+                                       CONSTRAINT check_report_status CHECK (status IN ('pending', 'resolved', 'dismissed'))
+);
+
+-- Пока жалоба не рассмотрена, пользователь не может пожаловаться на тот же объект повторно
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_pending_quote ON reports(quote_id, reporter_id) WHERE status = 'pending';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_pending_comment ON reports(comment_id, reporter_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_reports_status_created ON reports(status, created_at DESC);
+
+-- Скрытый контент не показывается в списках, пока его не проверит модератор
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_quotes_hidden_at ON quotes(hidden_at) WHERE hidden_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_hidden_at ON comments(hidden_at) WHERE hidden_at IS NOT NULL;
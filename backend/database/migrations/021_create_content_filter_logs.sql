@@ -0,0 +1,23 @@
+-- Журнал решений фильтра контента. Пишется только при срабатывании фильтра;
+-- для отклоненного текста объекта нет, поэтому quote_id/comment_id могут быть пустыми
+CREATE TABLE IF NOT EXISTS content_filter_logs (
+                                                   id SERIAL PRIMARY KEY,
+                                                   user_id INTEGER,
+                                                   target_type VARCHAR(10) NOT NULL,
+                                                   quote_id INTEGER,
+                                                   comment_id INTEGER,
+                                                   filter VARCHAR(30) NOT NULL,
+                                                   action VARCHAR(10) NOT NULL,
+                                                   reason VARCHAR(255) NOT NULL,
+                                                   terms TEXT,
+                                                   content TEXT NOT NULL,
+                                                   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                   FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+                                                   FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE SET NULL,
+                                                   FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE SET NULL,
+                                                   CONSTRAINT check_content_filter_log_target CHECK (target_type IN ('quote', 'comment')),
+                                                   CONSTRAINT check_content_filter_log_action CHECK (action IN ('mask', 'queue', 'reject'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_filter_logs_created_at ON content_filter_logs(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_content_filter_logs_user_id ON content_filter_logs(user_id);
@@ -0,0 +1,17 @@
+-- Поиск дублей цитат: точное совпадение после нормализации и похожие тексты через pg_trgm
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Нормализация: нижний регистр, ё -> е, пунктуация и пробелы схлопываются в один пробел.
+-- Та же функция используется в запросах, поэтому нормализация в SQL и в колонке совпадает
+CREATE OR REPLACE FUNCTION normalize_quote_content(content TEXT) RETURNS TEXT AS $$
+SELECT btrim(regexp_replace(replace(lower(content), 'ё', 'е'), '[^[:alnum:]]+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE STRICT;
+
+ALTER TABLE quotes
+    ADD COLUMN IF NOT EXISTS content_normalized TEXT
+        GENERATED ALWAYS AS (normalize_quote_content(content)) STORED;
+
+-- Точные дубли ищутся по hash-индексу (текст может быть длиннее лимита btree),
+-- похожие - по триграммному GIN-индексу
+CREATE INDEX IF NOT EXISTS idx_quotes_content_normalized ON quotes USING HASH (content_normalized);
+CREATE INDEX IF NOT EXISTS idx_quotes_content_normalized_trgm ON quotes USING GIN (content_normalized gin_trgm_ops);
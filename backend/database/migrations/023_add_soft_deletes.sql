@@ -0,0 +1,27 @@
+-- Мягкое удаление цитат и комментариев: строки остаются в корзине (deleted_at)
+-- и удаляются окончательно фоновой очисткой после срока хранения.
+-- deleted_by - кто удалил; удаленное модератором автор восстановить не может
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- Старые заглушки "[deleted]" становятся мягко удаленными комментариями:
+-- заглушка теперь строится при чтении, пока у комментария есть ответы.
+-- Выполняется один раз, чтобы повторный запуск не удалял восстановленные комментарии
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'comments' AND column_name = 'deleted_at') THEN
+        ALTER TABLE comments
+            ADD COLUMN deleted_at TIMESTAMP;
+
+        UPDATE comments SET deleted_at = CURRENT_TIMESTAMP WHERE is_deleted;
+    END IF;
+END $$;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- Корзина пользователя и очистка читают только удаленные строки
+CREATE INDEX IF NOT EXISTS idx_quotes_trash ON quotes(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_trash ON comments(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_quotes_deleted_at ON quotes(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Признак заглушки вычисляется при чтении по deleted_at (см. 023), колонка больше не нужна.
+-- 008 при повторном запуске добавляет ее снова, поэтому удаляем каждый раз
+ALTER TABLE comments DROP COLUMN IF EXISTS is_deleted;
@@ -1,20 +1,30 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"quotes-app/config"
+	"quotes-app/mailer"
 	"quotes-app/models"
+	"quotes-app/throttle"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Mailer mailer.Mailer
+	Guard  *throttle.LoginGuard
 }
 
 func NewAuthHandler() *AuthHandler {
-	return &AuthHandler{DB: config.DB}
+	return &AuthHandler{DB: config.DB, Mailer: config.Mailer, Guard: config.LoginGuard}
 }
 
 type RegisterRequest struct {
@@ -46,6 +56,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	user := models.User{
 		Username: req.Username,
 		Email:    req.Email,
+		Role:     models.RoleUser,
 	}
 
 	if err := user.SetPassword(req.Password); err != nil {
@@ -58,16 +69,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := config.GenerateToken(user.ID)
+	// Пока email не подтвержден, пользователь не может публиковать цитаты и комментарии
+	if err := sendVerificationEmail(h.DB, h.Mailer, &user); err != nil {
+		log.Printf("Failed to send verification email for user %d: %v", user.ID, err)
+	}
+
+	tokens, err := h.issueTokens(c, &user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"token": token,
-		"user":  user,
-	})
+	tokens["user"] = user
+	c.JSON(http.StatusCreated, tokens)
 }
 
 // Login аутентифицирует пользователя
@@ -78,25 +92,494 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	ip := c.ClientIP()
+
+	// Ошибки хранилища счетчиков не должны блокировать вход, поэтому только логируем их
+	wait, err := h.Guard.Check(req.Email, ip)
+	if err != nil {
+		log.Printf("Login guard check failed: %v", err)
+	}
+	if wait > 0 {
+		respondTooManyAttempts(c, wait)
+		return
+	}
+
 	var user models.User
 	if err := h.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		h.loginFailed(c, req.Email, ip)
 		return
 	}
 
 	if err := user.CheckPassword(req.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		h.loginFailed(c, req.Email, ip)
+		return
+	}
+
+	if err := h.Guard.Succeed(req.Email); err != nil {
+		log.Printf("Login guard reset failed: %v", err)
+	}
+
+	tokens, err := h.issueTokens(c, &user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	tokens["user"] = user
+	c.JSON(http.StatusOK, tokens)
+}
+
+// loginFailed учитывает неудачную попытку; неизвестный email считается так же, как неверный пароль
+func (h *AuthHandler) loginFailed(c *gin.Context, email, ip string) {
+	wait, err := h.Guard.Fail(email, ip)
+	if err != nil {
+		log.Printf("Login guard failure tracking failed: %v", err)
+	}
+	if wait > 0 {
+		respondTooManyAttempts(c, wait)
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+}
+
+func respondTooManyAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, try again later",
+		"retry_after": seconds,
+	})
+}
+
+// RefreshToken выдает новую пару токенов по refresh токену (с ротацией)
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokenHash := config.HashToken(req.RefreshToken)
+
+	var session models.Session
+	if err := h.DB.Where("refresh_token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+			return
+		}
+
+		// Повторное использование уже ротированного токена - вероятная утечка,
+		// поэтому отзываем всю сессию
+		now := time.Now()
+		h.DB.Model(&models.Session{}).
+			Where("previous_token_hash = ? AND revoked_at IS NULL", tokenHash).
+			Update("revoked_at", now)
+
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	if !session.IsActive(time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired or revoked"})
+		return
+	}
+
+	refreshToken, err := config.GenerateRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// Ротация: условие по старому хешу защищает от гонки двух одновременных refresh
+	result := h.DB.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, tokenHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  config.HashToken(refreshToken),
+			"previous_token_hash": tokenHash,
+			"last_used_at":        time.Now(),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	// Роль берем из БД, чтобы изменения прав применялись при обновлении токена
+	var user models.User
+	if err := h.DB.Select("id", "role").First(&user, session.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
 	}
 
-	token, err := config.GenerateToken(user.ID)
+	accessToken, err := config.GenerateToken(session.UserID, session.ID, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	c.JSON(http.StatusOK, tokenResponse(accessToken, refreshToken))
+}
+
+// Logout отзывает текущую сессию
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID.(uint)).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll отзывает все сессии пользователя
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	result := h.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID.(uint)).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  user,
+		"message":          "Logged out from all sessions",
+		"revoked_sessions": result.RowsAffected,
 	})
 }
+
+// VerifyEmail подтверждает email по токену из письма
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	rawToken := c.Query("token")
+	if rawToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	var user models.User
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeUserToken(tx, rawToken, models.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&user).Update("email_verified_at", time.Now()).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, errInvalidUserToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+		"user":    user,
+	})
+}
+
+// ResendVerification повторно отправляет письмо подтверждения
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsEmailVerified() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := sendVerificationEmail(h.DB, h.Mailer, &user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// ChangePassword - смена пароля с проверкой текущего; остальные сессии отзываются
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := user.CheckPassword(req.CurrentPassword); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if err := user.SetPassword(req.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_hash", user.PasswordHash).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", user.ID, c.GetUint("session_id")).
+			Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// ForgotPassword отправляет ссылку для сброса пароля.
+// Ответ одинаковый независимо от того, существует ли email.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.PasswordForgotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If this email is registered, a password reset link has been sent"}
+
+	var user models.User
+	if err := h.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, err := createUserToken(h.DB, user.ID, models.TokenPurposePasswordReset, config.PasswordResetTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
+		return
+	}
+
+	sendMail(h.Mailer, mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля Quotes App",
+		Body: "Чтобы задать новый пароль, перейдите по ссылке:\n" +
+			config.AppBaseURL + "/reset-password?token=" + token + "\n\n" +
+			"Ссылка действует " + config.PasswordResetTTL.String() + ". " +
+			"Если вы не запрашивали сброс, просто проигнорируйте это письмо.",
+	})
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword задает новый пароль по одноразовому токену и отзывает все сессии
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeUserToken(tx, req.Token, models.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return err
+		}
+		if err := user.SetPassword(req.NewPassword); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("password_hash", user.PasswordHash).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", time.Now()).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, errInvalidUserToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+var errInvalidUserToken = errors.New("invalid or expired token")
+
+// createUserToken выпускает одноразовый токен; прежние неиспользованные токены того же назначения гасятся
+func createUserToken(tx *gorm.DB, userID uint, purpose string, ttl time.Duration) (string, error) {
+	token, err := config.GenerateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: config.HashToken(token),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeUserToken атомарно помечает токен использованным и возвращает его
+func consumeUserToken(tx *gorm.DB, rawToken, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	if err := tx.Where("token_hash = ? AND purpose = ?", config.HashToken(rawToken), purpose).
+		First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidUserToken
+		}
+		return nil, err
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, errInvalidUserToken
+	}
+
+	// Условие used_at IS NULL защищает от двойного использования при гонке
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errInvalidUserToken
+	}
+
+	return &token, nil
+}
+
+// sendMail отправляет письмо в фоне, чтобы время ответа не зависело от почтового сервера
+func sendMail(m mailer.Mailer, msg mailer.Message) {
+	go func() {
+		if err := m.Send(msg); err != nil {
+			log.Printf("Failed to send mail to %s: %v", msg.To, err)
+		}
+	}()
+}
+
+// sendVerificationEmail выпускает токен подтверждения и отправляет ссылку на текущий email пользователя
+func sendVerificationEmail(db *gorm.DB, m mailer.Mailer, user *models.User) error {
+	token, err := createUserToken(db, user.ID, models.TokenPurposeEmailVerification, config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	sendMail(m, mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email в Quotes App",
+		Body: "Чтобы подтвердить email, перейдите по ссылке:\n" +
+			config.AppBaseURL + "/verify-email?token=" + token + "\n\n" +
+			"Ссылка действует " + config.EmailVerificationTTL.String() + ".",
+	})
+	return nil
+}
+
+// issueTokens создает новую сессию и выдает для нее access и refresh токены
+func (h *AuthHandler) issueTokens(c *gin.Context, user *models.User) (gin.H, error) {
+	refreshToken, err := config.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: config.HashToken(refreshToken),
+		UserAgent:        truncate(c.Request.UserAgent(), 255),
+		IPAddress:        c.ClientIP(),
+		ExpiresAt:        time.Now().Add(config.RefreshTokenTTL),
+	}
+	if err := h.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, err := config.GenerateToken(user.ID, session.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(accessToken, refreshToken), nil
+}
+
+func tokenResponse(accessToken, refreshToken string) gin.H {
+	return gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int(config.AccessTokenTTL.Seconds()),
+	}
+}
+
+// truncate обрезает строку до max байт по границе символа. Невалидные байты UTF-8
+// (заголовки приходят как есть) убираются, иначе Postgres не примет строку
+func truncate(s string, max int) string {
+	s = strings.ToValidUTF8(s, "")
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"short", "curl/8.0", 255, "curl/8.0"},
+		{"ascii", "abcdef", 4, "abcd"},
+		{"cut inside cyrillic rune", "абв", 3, "а"},
+		{"cut on rune boundary", "абв", 4, "аб"},
+		{"cut inside emoji", "a🙂", 3, "a"},
+		{"invalid bytes are dropped", "ok\xffok", 255, "okok"},
+		{"zero", "абв", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.max)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+			}
+			if !utf8.ValidString(got) || len(got) > tt.max {
+				t.Errorf("truncate(%q, %d) = %q is not valid UTF-8 within %d bytes", tt.s, tt.max, got, tt.max)
+			}
+		})
+	}
+
+	long := strings.Repeat("ж", 200)
+	if got := truncate(long, 255); !utf8.ValidString(got) || len(got) != 254 {
+		t.Errorf("truncate(200 x ж, 255) has %d bytes, want 254", len(got))
+	}
+}
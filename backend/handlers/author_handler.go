@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvalidAuthorName  = errors.New("invalid author name")
+	errAuthorNameTaken    = errors.New("author name taken")
+	errInvalidAuthorYears = errors.New("invalid author years")
+)
+
+type AuthorHandler struct {
+	DB *gorm.DB
+}
+
+func NewAuthorHandler() *AuthorHandler {
+	return &AuthorHandler{DB: config.DB}
+}
+
+// authorSortFields - whitelist полей сортировки авторов
+var authorSortFields = map[string]cursorColumn{
+	"quotes_count": {Expr: "quotes_count", IDColumn: "authors.id", Kind: cursorKindInt},
+	"name":         {Expr: "authors.name", IDColumn: "authors.id", Kind: cursorKindString},
+}
+
+const authorQuotesCountSQL = "(SELECT COUNT(*) FROM quotes WHERE quotes.author_id = authors.id AND quotes.deleted_at IS NULL) AS quotes_count"
+
+// GetAuthors - список авторов с количеством цитат; q ищет по имени и псевдонимам
+func (h *AuthorHandler) GetAuthors(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{SortFields: authorSortFields, DefaultSort: "quotes_count", DefaultLimit: 20})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	query := h.DB.Model(&models.Author{})
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query = query.Where(`authors.name ILIKE ? OR EXISTS (SELECT 1 FROM author_aliases
+			WHERE author_aliases.author_id = authors.id AND author_aliases.name ILIKE ?)`, "%"+q+"%", "%"+q+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch authors"})
+		return
+	}
+
+	var authors []models.Author
+	if err := query.Select("authors.*, " + authorQuotesCountSQL).
+		Preload("Aliases").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&authors).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch authors"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"authors":    authors,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// GetAuthorByID - страница автора: биография, статистика и цитаты с пагинацией
+func (h *AuthorHandler) GetAuthorByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author ID"})
+		return
+	}
+
+	params, errs := parseListParams(c, listOptions{SortFields: quoteSortFields, DefaultSort: "score"})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var author models.Author
+	if err := h.DB.Preload("Aliases").First(&author, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Author not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch author"})
+		return
+	}
+
+	// Статистика и пагинация считаются по тем же видимым цитатам, что и список
+	var stats models.AuthorStats
+	if err := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).
+		Select("COUNT(*) AS quotes_count, COALESCE(SUM(likes_count), 0) AS likes_count, COALESCE(SUM(dislikes_count), 0) AS dislikes_count").
+		Where("author_id = ?", author.ID).
+		Scan(&stats).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch author stats"})
+		return
+	}
+	author.QuotesCount = stats.QuotesCount
+
+	var quotes []models.Quote
+	if err := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).Where("author_id = ?", author.ID).
+		Preload("User").Preload("Category").Preload("Tags").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&quotes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"author":     author,
+		"stats":      stats,
+		"quotes":     quotes,
+		"pagination": paginationMeta(params.Page, params.Limit, stats.QuotesCount),
+	})
+}
+
+// CreateAuthor - создание автора с псевдонимами (только admin)
+func (h *AuthorHandler) CreateAuthor(c *gin.Context) {
+	var input models.AuthorCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !validAuthorYears(input.BirthYear, input.DeathYear) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "death_year must not be earlier than birth_year"})
+		return
+	}
+
+	author := models.Author{
+		Name:      strings.TrimSpace(input.Name),
+		NameKey:   normalizeAuthorKey(input.Name),
+		Bio:       input.Bio,
+		BirthYear: input.BirthYear,
+		DeathYear: input.DeathYear,
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if author.NameKey == "" {
+			return errInvalidAuthorName
+		}
+		if authorKeyTaken(tx, author.NameKey, 0) {
+			return errAuthorNameTaken
+		}
+
+		aliases, err := buildAliases(tx, input.Aliases, 0, author.NameKey)
+		if err != nil {
+			return err
+		}
+		author.Aliases = aliases
+
+		return tx.Create(&author).Error
+	})
+
+	if err != nil {
+		respondAuthorError(c, err, "Failed to create author")
+		return
+	}
+
+	c.JSON(http.StatusCreated, author)
+}
+
+// UpdateAuthor - изменение автора (только admin).
+// Старое имя при переименовании остается псевдонимом, чтобы по нему продолжали находиться цитаты.
+func (h *AuthorHandler) UpdateAuthor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author ID"})
+		return
+	}
+
+	var input models.AuthorUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var author models.Author
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Aliases").First(&author, id).Error; err != nil {
+			return err
+		}
+
+		birthYear, deathYear := author.BirthYear, author.DeathYear
+		if input.BirthYear != nil {
+			birthYear = input.BirthYear
+		}
+		if input.DeathYear != nil {
+			deathYear = input.DeathYear
+		}
+		if !validAuthorYears(birthYear, deathYear) {
+			return errInvalidAuthorYears
+		}
+
+		// Обновляем только переданные поля
+		updates := map[string]interface{}{
+			"birth_year": birthYear,
+			"death_year": deathYear,
+		}
+		if input.Bio != nil {
+			updates["bio"] = *input.Bio
+		}
+
+		oldName, oldKey := author.Name, author.NameKey
+		newKey := oldKey
+		if input.Name != "" {
+			newKey = normalizeAuthorKey(input.Name)
+			if newKey == "" {
+				return errInvalidAuthorName
+			}
+			if newKey != oldKey && authorKeyTaken(tx, newKey, author.ID) {
+				return errAuthorNameTaken
+			}
+			updates["name"] = strings.TrimSpace(input.Name)
+			updates["name_key"] = newKey
+		}
+
+		aliasNames := make([]string, 0, len(author.Aliases)+1)
+		if input.Aliases != nil {
+			aliasNames = append(aliasNames, *input.Aliases...)
+		} else {
+			for _, alias := range author.Aliases {
+				aliasNames = append(aliasNames, alias.Name)
+			}
+		}
+		if newKey != oldKey {
+			aliasNames = append(aliasNames, oldName)
+		}
+
+		if err := tx.Model(&author).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Псевдонимы пересоздаем целиком: свои старые записи не должны мешать проверке уникальности
+		if err := tx.Where("author_id = ?", author.ID).Delete(&models.AuthorAlias{}).Error; err != nil {
+			return err
+		}
+		aliases, err := buildAliases(tx, aliasNames, author.ID, newKey)
+		if err != nil {
+			return err
+		}
+		if len(aliases) > 0 {
+			if err := tx.Create(&aliases).Error; err != nil {
+				return err
+			}
+		}
+
+		// Quote.Author хранит каноническое имя, в том числе у цитат в корзине
+		if name, renamed := updates["name"]; renamed {
+			if err := tx.Unscoped().Model(&models.Quote{}).Where("author_id = ?", author.ID).Update("author", name).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Preload("Aliases").First(&author, author.ID).Error
+	})
+
+	if err != nil {
+		respondAuthorError(c, err, "Failed to update author")
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
+
+// MergeAuthor - перенос цитат и псевдонимов к целевому автору и удаление исходного (только admin).
+// Имя исходного автора становится псевдонимом целевого.
+func (h *AuthorHandler) MergeAuthor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author ID"})
+		return
+	}
+
+	var input models.AuthorMergeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.TargetID == uint(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge author into itself"})
+		return
+	}
+
+	var target models.Author
+	var movedQuotes int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var source models.Author
+		if err := tx.First(&source, id).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, input.TargetID).Error; err != nil {
+			return err
+		}
+
+		// Unscoped: цитаты из корзины тоже переносим, иначе после восстановления они останутся без автора
+		result := tx.Unscoped().Model(&models.Quote{}).
+			Where("author_id = ?", source.ID).
+			Updates(map[string]interface{}{"author_id": target.ID, "author": target.Name})
+		if result.Error != nil {
+			return result.Error
+		}
+		movedQuotes = result.RowsAffected
+
+		if err := tx.Model(&models.AuthorAlias{}).
+			Where("author_id = ?", source.ID).
+			Update("author_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		// Сначала удаляем источник, чтобы освободить его name_key для псевдонима
+		if err := tx.Delete(&source).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.AuthorAlias{
+			AuthorID: target.ID,
+			Name:     source.Name,
+			NameKey:  source.NameKey,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Preload("Aliases").First(&target, target.ID).Error
+	})
+
+	if err != nil {
+		respondAuthorError(c, err, "Failed to merge authors")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Authors merged successfully",
+		"author":       target,
+		"moved_quotes": movedQuotes,
+	})
+}
+
+// respondAuthorError переводит ошибки операций с авторами в HTTP ответы
+func respondAuthorError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Author not found"})
+	case errors.Is(err, errInvalidAuthorName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Author name must contain letters or digits"})
+	case errors.Is(err, errInvalidAuthorYears):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "death_year must not be earlier than birth_year"})
+	case errors.Is(err, errAuthorNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Author name or alias is already taken"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// normalizeAuthorKey - ключ сравнения имен: нижний регистр, точки и запятые
+// заменяются пробелами, пробелы схлопываются. Должен совпадать с выражением
+// в миграции 015_create_authors.sql
+func normalizeAuthorKey(name string) string {
+	name = strings.NewReplacer(".", " ", ",", " ").Replace(name)
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// resolveAuthor находит автора по имени или псевдониму, а если такого нет - создает его
+func resolveAuthor(tx *gorm.DB, name string) (*models.Author, error) {
+	key := normalizeAuthorKey(name)
+	if key == "" {
+		return nil, errInvalidAuthorName
+	}
+
+	var author models.Author
+	err := tx.Where("name_key = ?", key).
+		Or("id IN (SELECT author_id FROM author_aliases WHERE name_key = ?)", key).
+		First(&author).Error
+	if err == nil {
+		return &author, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	author = models.Author{Name: strings.TrimSpace(name), NameKey: key}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name_key"}},
+		DoNothing: true,
+	}).Create(&author).Error; err != nil {
+		return nil, err
+	}
+
+	// Автор мог быть создан параллельным запросом
+	if author.ID == 0 {
+		if err := tx.Where("name_key = ?", key).First(&author).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &author, nil
+}
+
+// authorKeyTaken проверяет, занят ли ключ имени другим автором или чужим псевдонимом
+func authorKeyTaken(tx *gorm.DB, key string, exceptAuthorID uint) bool {
+	var count int64
+	tx.Model(&models.Author{}).Where("name_key = ? AND id <> ?", key, exceptAuthorID).Count(&count)
+	if count > 0 {
+		return true
+	}
+
+	tx.Model(&models.AuthorAlias{}).Where("name_key = ? AND author_id <> ?", key, exceptAuthorID).Count(&count)
+	return count > 0
+}
+
+// buildAliases проверяет псевдонимы и собирает записи; совпадающие с именем и повторы пропускаются
+func buildAliases(tx *gorm.DB, names []string, authorID uint, ownKey string) ([]models.AuthorAlias, error) {
+	aliases := make([]models.AuthorAlias, 0, len(names))
+	seen := map[string]bool{ownKey: true}
+
+	for _, name := range names {
+		key := normalizeAuthorKey(name)
+		if key == "" {
+			return nil, errInvalidAuthorName
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if authorKeyTaken(tx, key, authorID) {
+			return nil, errAuthorNameTaken
+		}
+
+		aliases = append(aliases, models.AuthorAlias{
+			AuthorID: authorID,
+			Name:     strings.TrimSpace(name),
+			NameKey:  key,
+		})
+	}
+
+	return aliases, nil
+}
+
+// validAuthorYears - год смерти не раньше года рождения
+func validAuthorYears(birthYear, deathYear *int) bool {
+	return birthYear == nil || deathYear == nil || *deathYear >= *birthYear
+}
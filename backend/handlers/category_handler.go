@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"quotes-app/config"
 	"quotes-app/models"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -28,3 +30,244 @@ func (h *CategoryHandler) GetCategories(c *gin.Context) {
 
 	c.JSON(http.StatusOK, categories)
 }
+
+// GetCategoryByID - получение категории с количеством цитат
+func (h *CategoryHandler) GetCategoryByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var category models.Category
+	if err := h.DB.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category"})
+		return
+	}
+
+	var quotesCount int64
+	if err := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).Where("category_id = ?", category.ID).Count(&quotesCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":           category.ID,
+		"name":         category.Name,
+		"description":  category.Description,
+		"quotes_count": quotesCount,
+	})
+}
+
+// GetCategoryQuotes - цитаты категории с пагинацией
+func (h *CategoryHandler) GetCategoryQuotes(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	params, errs := parseListParams(c, listOptions{SortFields: quoteSortFields, DefaultSort: "created_at"})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var category models.Category
+	if err := h.DB.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	query := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).Where("category_id = ?", category.ID)
+
+	var total int64
+	query.Count(&total)
+
+	var quotes []models.Quote
+	if err := query.Preload("User").Preload("Category").Preload("Tags").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&quotes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotes":     quotes,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// CreateCategory - создание категории (только admin)
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var input models.CategoryCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.nameTaken(input.Name, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Category with this name already exists"})
+		return
+	}
+
+	category := models.Category{
+		Name:        input.Name,
+		Description: input.Description,
+	}
+
+	if err := h.DB.Create(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory - обновление категории (только admin)
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var category models.Category
+	if err := h.DB.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	var input models.CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Обновляем только переданные поля
+	updates := make(map[string]interface{})
+	if input.Name != "" && input.Name != category.Name {
+		if h.nameTaken(input.Name, category.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Category with this name already exists"})
+			return
+		}
+		updates["name"] = input.Name
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+
+	if len(updates) > 0 {
+		if err := h.DB.Model(&category).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory - удаление категории (только admin).
+// Категорию с цитатами удаляем только с force=true, иначе нужно сначала объединить ее с другой.
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var category models.Category
+	if err := h.DB.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	// Цитаты в корзине тоже считаются: после восстановления они вернутся в категорию
+	var quotesCount int64
+	h.DB.Unscoped().Model(&models.Quote{}).Where("category_id = ?", category.ID).Count(&quotesCount)
+	if quotesCount > 0 && c.Query("force") != "true" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        "Category has quotes; merge it into another category or use force=true",
+			"quotes_count": quotesCount,
+		})
+		return
+	}
+
+	// Цитаты удаленной категории остаются без категории (ON DELETE SET NULL)
+	if err := h.DB.Delete(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+// MergeCategory - перенос всех цитат в целевую категорию и удаление исходной (только admin)
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var input models.CategoryMergeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.TargetID == uint(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge category into itself"})
+		return
+	}
+
+	var target models.Category
+	var movedQuotes int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var source models.Category
+		if err := tx.First(&source, id).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, input.TargetID).Error; err != nil {
+			return err
+		}
+
+		// Unscoped: цитаты из корзины тоже переносим, иначе после восстановления они останутся без категории
+		result := tx.Unscoped().Model(&models.Quote{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		movedQuotes = result.RowsAffected
+
+		return tx.Delete(&source).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Categories merged successfully",
+		"category":     target,
+		"moved_quotes": movedQuotes,
+	})
+}
+
+// nameTaken проверяет, занято ли имя другой категорией
+func (h *CategoryHandler) nameTaken(name string, exceptID uint) bool {
+	var count int64
+	h.DB.Model(&models.Category{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count)
+	return count > 0
+}
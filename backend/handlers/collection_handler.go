@@ -0,0 +1,522 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCollectionQuotes - сколько цитат можно положить в одну коллекцию
+const maxCollectionQuotes = 1000
+
+var (
+	errCollectionForbidden = errors.New("collection belongs to another user")
+	errQuoteNotFound       = errors.New("quote not found")
+	errCollectionFull      = errors.New("collection is full")
+	errQuoteInCollection   = errors.New("quote already in collection")
+	errQuoteNotInList      = errors.New("quote not in collection")
+	errInvalidQuoteOrder   = errors.New("invalid quote order")
+)
+
+// visibleCollectionQuote отсекает элементы коллекции, чьи цитаты лежат в корзине или скрыты по жалобам
+const visibleCollectionQuote = "quote_id IN (SELECT id FROM quotes WHERE deleted_at IS NULL AND hidden_at IS NULL)"
+
+const collectionQuotesCountSQL = "(SELECT COUNT(*) FROM collection_quotes JOIN quotes ON quotes.id = collection_quotes.quote_id WHERE collection_quotes.collection_id = collections.id AND quotes.deleted_at IS NULL AND quotes.hidden_at IS NULL) AS quotes_count"
+
+type CollectionHandler struct {
+	DB *gorm.DB
+}
+
+func NewCollectionHandler() *CollectionHandler {
+	return &CollectionHandler{DB: config.DB}
+}
+
+// GetMyCollections - все коллекции текущего пользователя, включая приватные
+func (h *CollectionHandler) GetMyCollections(c *gin.Context) {
+	h.listCollections(c, c.GetUint("user_id"), true)
+}
+
+// GetUserCollections - публичные коллекции пользователя
+func (h *CollectionHandler) GetUserCollections(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.PublicUser
+	if err := h.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	h.listCollections(c, user.ID, false)
+}
+
+func (h *CollectionHandler) listCollections(c *gin.Context, userID uint, includePrivate bool) {
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	query := h.DB.Model(&models.Collection{}).Where("user_id = ?", userID)
+	if !includePrivate {
+		query = query.Where("visibility = ?", models.CollectionPublic)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+
+	var collections []models.Collection
+	if err := query.Select("collections.*, " + collectionQuotesCountSQL).
+		Order("updated_at DESC, id DESC").
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&collections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collections": collections,
+		"pagination":  paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// GetCollection - коллекция с цитатами в пользовательском порядке.
+// Приватная коллекция видна только владельцу, для остальных - 404.
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var collection models.Collection
+	if err := h.DB.Select("collections.*, "+collectionQuotesCountSQL).
+		Preload("User").
+		First(&collection, id).Error; err != nil || !collection.IsVisibleTo(c.GetUint("user_id")) {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection"})
+		return
+	}
+
+	// Цитаты из корзины и скрытые по жалобам не показываются, но потом возвращаются на свое место
+	var items []models.CollectionQuote
+	if err := h.DB.Where("collection_id = ?", collection.ID).
+		Where(visibleCollectionQuote).
+		Preload("Quote").Preload("Quote.User").Preload("Quote.Category").Preload("Quote.Tags").
+		Order("position ASC, added_at ASC").
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": collection,
+		"quotes":     items,
+		"pagination": paginationMeta(params.Page, params.Limit, collection.QuotesCount),
+	})
+}
+
+// CreateCollection - создание коллекции (по умолчанию приватной)
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	var input models.CollectionCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection := models.Collection{
+		UserID:      c.GetUint("user_id"),
+		Name:        input.Name,
+		Description: input.Description,
+		Visibility:  input.Visibility,
+	}
+	if collection.Visibility == "" {
+		collection.Visibility = models.CollectionPrivate
+	}
+
+	if err := h.DB.Create(&collection).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// UpdateCollection - изменение названия, описания или видимости (только владелец)
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	collection, ok := h.ownCollection(c)
+	if !ok {
+		return
+	}
+
+	var input models.CollectionUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Обновляем только переданные поля
+	updates := make(map[string]interface{})
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.Visibility != "" {
+		updates["visibility"] = input.Visibility
+	}
+
+	if len(updates) > 0 {
+		if err := h.DB.Model(collection).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collection"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection - удаление коллекции (только владелец); сами цитаты не удаляются
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	collection, ok := h.ownCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(collection).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// AddQuote - добавление цитаты в коллекцию: в конец или на указанную позицию
+func (h *CollectionHandler) AddQuote(c *gin.Context) {
+	collectionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	var input models.CollectionAddQuoteRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var item models.CollectionQuote
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		collection, err := lockCollection(tx, uint(collectionID), c.GetUint("user_id"))
+		if err != nil {
+			return err
+		}
+
+		// Скрытую по жалобам цитату добавить нельзя, и ответ не должен выдавать, что она существует
+		var quote models.Quote
+		if err := tx.Select("id", "user_id", "hidden_at").First(&quote, input.QuoteID).Error; err != nil ||
+			!canSeeQuote(c, &quote) {
+			return errQuoteNotFound
+		}
+
+		// Цитаты из корзины занимают место в лимите: после восстановления они вернутся в коллекцию
+		slots, err := collectionSlots(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+		if len(slots) >= maxCollectionQuotes {
+			return errCollectionFull
+		}
+
+		if slices.ContainsFunc(slots, func(slot collectionSlot) bool { return slot.QuoteID == quote.ID }) {
+			return errQuoteInCollection
+		}
+
+		// Без позиции - в конец; позиция за концом списка тоже означает конец.
+		// Позиция задается среди видимых цитат: цитата встает перед той, что сейчас на этом месте
+		position := 1
+		if len(slots) > 0 {
+			position = slots[len(slots)-1].Position + 1
+		}
+		if input.Position != nil {
+			live := make([]int, 0, len(slots))
+			for _, slot := range slots {
+				if !slot.Trashed && !slot.Hidden {
+					live = append(live, slot.Position)
+				}
+			}
+			if *input.Position > len(live) {
+				input.Position = nil
+			} else {
+				position = live[*input.Position-1]
+			}
+		}
+		if input.Position != nil {
+			if err := tx.Model(&models.CollectionQuote{}).
+				Where("collection_id = ? AND position >= ?", collection.ID, position).
+				Update("position", gorm.Expr("position + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		item = models.CollectionQuote{CollectionID: collection.ID, QuoteID: quote.ID, Position: position}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+
+		return touchCollection(tx, collection)
+	})
+
+	if err != nil {
+		respondCollectionError(c, err, "Failed to add quote to collection")
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// RemoveQuote - удаление цитаты из коллекции; позиции следующих цитат сдвигаются
+func (h *CollectionHandler) RemoveQuote(c *gin.Context) {
+	collectionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	quoteID, err := strconv.Atoi(c.Param("quote_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		collection, err := lockCollection(tx, uint(collectionID), c.GetUint("user_id"))
+		if err != nil {
+			return err
+		}
+
+		var item models.CollectionQuote
+		if err := tx.Where("collection_id = ? AND quote_id = ?", collection.ID, quoteID).First(&item).Error; err != nil {
+			return errQuoteNotInList
+		}
+
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.CollectionQuote{}).
+			Where("collection_id = ? AND position > ?", collection.ID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+
+		return touchCollection(tx, collection)
+	})
+
+	if err != nil {
+		respondCollectionError(c, err, "Failed to remove quote from collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote removed from collection"})
+}
+
+// ReorderQuotes - задает новый порядок цитат; нужно передать все цитаты коллекции
+func (h *CollectionHandler) ReorderQuotes(c *gin.Context) {
+	collectionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	var input models.CollectionReorderRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		collection, err := lockCollection(tx, uint(collectionID), c.GetUint("user_id"))
+		if err != nil {
+			return err
+		}
+
+		slots, err := collectionSlots(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+
+		order, err := reorderSlots(slots, input.QuoteIDs)
+		if err != nil {
+			return err
+		}
+
+		for i, id := range order {
+			if err := tx.Model(&models.CollectionQuote{}).
+				Where("collection_id = ? AND quote_id = ?", collection.ID, id).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchCollection(tx, collection)
+	})
+
+	if err != nil {
+		respondCollectionError(c, err, "Failed to reorder collection")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection reordered successfully"})
+}
+
+// collectionSlot - место цитаты в коллекции; Trashed - цитата лежит в корзине, Hidden - скрыта по жалобам
+type collectionSlot struct {
+	QuoteID  uint
+	Position int
+	Trashed  bool
+	Hidden   bool
+}
+
+// collectionSlots - все элементы коллекции по порядку, включая цитаты из корзины
+func collectionSlots(tx *gorm.DB, collectionID uint) ([]collectionSlot, error) {
+	var slots []collectionSlot
+	err := tx.Table("collection_quotes").
+		Select("collection_quotes.quote_id, collection_quotes.position, quotes.deleted_at IS NOT NULL AS trashed, quotes.hidden_at IS NOT NULL AS hidden").
+		Joins("JOIN quotes ON quotes.id = collection_quotes.quote_id").
+		Where("collection_quotes.collection_id = ?", collectionID).
+		Order("collection_quotes.position ASC, collection_quotes.added_at ASC").
+		Scan(&slots).Error
+	return slots, err
+}
+
+// reorderSlots возвращает новый порядок всех элементов коллекции.
+// quoteIDs должен содержать все видимые цитаты коллекции; скрытые по жалобам
+// передавать можно, но не обязательно. Цитаты из корзины и непереданные скрытые
+// остаются на своих местах, чтобы потом вернуться туда же.
+func reorderSlots(slots []collectionSlot, quoteIDs []uint) ([]uint, error) {
+	requested := make(map[uint]bool, len(quoteIDs))
+	for _, id := range quoteIDs {
+		if requested[id] {
+			return nil, errInvalidQuoteOrder
+		}
+		requested[id] = true
+	}
+
+	movable := 0
+	for _, slot := range slots {
+		if slot.Trashed {
+			if requested[slot.QuoteID] {
+				return nil, errInvalidQuoteOrder
+			}
+			continue
+		}
+		if requested[slot.QuoteID] {
+			movable++
+		} else if !slot.Hidden {
+			return nil, errInvalidQuoteOrder
+		}
+	}
+	// Все переданные цитаты должны быть в коллекции
+	if movable != len(quoteIDs) {
+		return nil, errInvalidQuoteOrder
+	}
+
+	order := make([]uint, 0, len(slots))
+	next := 0
+	for _, slot := range slots {
+		if !requested[slot.QuoteID] {
+			order = append(order, slot.QuoteID)
+			continue
+		}
+		order = append(order, quoteIDs[next])
+		next++
+	}
+	return order, nil
+}
+
+// ownCollection загружает коллекцию из :id и проверяет, что она принадлежит текущему пользователю
+func (h *CollectionHandler) ownCollection(c *gin.Context) (*models.Collection, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return nil, false
+	}
+
+	var collection models.Collection
+	if err := h.DB.First(&collection, id).Error; err != nil {
+		respondCollectionError(c, err, "Failed to fetch collection")
+		return nil, false
+	}
+
+	if collection.UserID != c.GetUint("user_id") {
+		respondCollectionError(c, errCollectionForbidden, "")
+		return nil, false
+	}
+
+	return &collection, true
+}
+
+// lockCollection блокирует строку коллекции до конца транзакции,
+// чтобы параллельные изменения не перепутали позиции
+func lockCollection(tx *gorm.DB, id, userID uint) (*models.Collection, error) {
+	var collection models.Collection
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&collection, id).Error; err != nil {
+		return nil, err
+	}
+	if collection.UserID != userID {
+		return nil, errCollectionForbidden
+	}
+	return &collection, nil
+}
+
+// touchCollection обновляет updated_at при изменении содержимого
+func touchCollection(tx *gorm.DB, collection *models.Collection) error {
+	return tx.Model(collection).Update("updated_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
+
+// respondCollectionError переводит ошибки операций с коллекциями в HTTP ответы
+func respondCollectionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+	case errors.Is(err, errCollectionForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only modify your own collections"})
+	case errors.Is(err, errQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+	case errors.Is(err, errQuoteNotInList):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote is not in this collection"})
+	case errors.Is(err, errQuoteInCollection):
+		c.JSON(http.StatusConflict, gin.H{"error": "Quote is already in this collection"})
+	case errors.Is(err, errCollectionFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "Collection cannot contain more than " + strconv.Itoa(maxCollectionQuotes) + " quotes"})
+	case errors.Is(err, errInvalidQuoteOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quote_ids must list every quote of the collection exactly once"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
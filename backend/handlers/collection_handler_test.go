@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestReorderSlots(t *testing.T) {
+	slots := []collectionSlot{
+		{QuoteID: 1, Position: 1},
+		{QuoteID: 2, Position: 2, Trashed: true},
+		{QuoteID: 3, Position: 3},
+		{QuoteID: 4, Position: 4, Hidden: true},
+		{QuoteID: 5, Position: 5},
+	}
+
+	tests := []struct {
+		name     string
+		quoteIDs []uint
+		want     []uint
+		wantErr  bool
+	}{
+		{"trashed and omitted hidden keep their places", []uint{5, 3, 1}, []uint{5, 2, 3, 4, 1}, false},
+		{"hidden quote may be moved", []uint{4, 5, 3, 1}, []uint{4, 2, 5, 3, 1}, false},
+		{"same order", []uint{1, 3, 5}, []uint{1, 2, 3, 4, 5}, false},
+		{"missing visible quote", []uint{5, 1}, nil, true},
+		{"trashed quote cannot be moved", []uint{2, 5, 3, 1}, nil, true},
+		{"unknown quote", []uint{5, 3, 1, 9}, nil, true},
+		{"duplicate quote", []uint{5, 3, 1, 1}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reorderSlots(slots, tt.quoteIDs)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidQuoteOrder) {
+					t.Fatalf("reorderSlots() error = %v, want errInvalidQuoteOrder", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reorderSlots() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("reorderSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,19 +4,26 @@ import (
 	"errors"
 	"net/http"
 	"quotes-app/config"
+	"quotes-app/contentfilter"
 	"quotes-app/models"
+	"quotes-app/realtime"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CommentHandler struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Hub    *realtime.Hub
+	Filter *contentfilter.Pipeline
 }
 
 func NewCommentHandler() *CommentHandler {
-	return &CommentHandler{DB: config.DB}
+	return &CommentHandler{DB: config.DB, Hub: config.Hub, Filter: config.ContentFilter}
 }
 
 // AddComment - добавление комментария к цитате
@@ -41,26 +48,132 @@ func (h *CommentHandler) AddComment(c *gin.Context) {
 		return
 	}
 
-	// Проверяем существование цитаты
+	// Проверяем существование цитаты; скрытую по жалобам комментируют только автор и модераторы
 	var quote models.Quote
-	if err := h.DB.First(&quote, quoteID).Error; err != nil {
+	if err := h.DB.First(&quote, quoteID).Error; err != nil || !canSeeQuote(c, &quote) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
 		return
 	}
 
-	comment := models.Comment{
+	comment, ok := h.createComment(c, models.Comment{
 		Content: input.Content,
 		QuoteID: uint(quoteID),
 		UserID:  &userIDUint,
+	})
+	if !ok {
+		return
+	}
+
+	notify(h.DB, models.NotificationEvent{
+		RecipientID: quote.UserID,
+		ActorID:     userIDUint,
+		Type:        models.NotificationQuoteComment,
+		QuoteID:     &quote.ID,
+		CommentID:   &comment.ID,
+		GroupKey:    notificationGroup(models.NotificationQuoteComment, quote.ID),
+	})
+}
+
+// AddReply - ответ на комментарий
+func (h *CommentHandler) AddReply(c *gin.Context) {
+	parentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userIDUint := userID.(uint)
+
+	var input models.CommentCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Скрытый по жалобам комментарий (и комментарии скрытой цитаты) видят только автор и модераторы
+	var parent models.Comment
+	if err := h.DB.First(&parent, parentID).Error; err != nil ||
+		(parent.HiddenAt != nil && !canModify(c, parent.UserID, userIDUint)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	// Цитата в корзине не находится - отвечать в ее ветке нельзя
+	var quote models.Quote
+	if err := h.DB.First(&quote, parent.QuoteID).Error; err != nil || !canSeeQuote(c, &quote) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	comment, ok := h.createComment(c, models.Comment{
+		Content:  input.Content,
+		QuoteID:  parent.QuoteID,
+		ParentID: &parent.ID,
+		UserID:   &userIDUint,
+	})
+	if !ok {
+		return
+	}
+
+	notify(h.DB, models.NotificationEvent{
+		RecipientID: parent.UserID,
+		ActorID:     userIDUint,
+		Type:        models.NotificationCommentReply,
+		QuoteID:     &parent.QuoteID,
+		CommentID:   &comment.ID,
+		GroupKey:    notificationGroup(models.NotificationCommentReply, parent.ID),
+	})
+
+	// Автор цитаты тоже узнает о новом комментарии, если это не он написал родительский
+	if quote.UserID == nil || parent.UserID == nil || *quote.UserID != *parent.UserID {
+		notify(h.DB, models.NotificationEvent{
+			RecipientID: quote.UserID,
+			ActorID:     userIDUint,
+			Type:        models.NotificationQuoteComment,
+			QuoteID:     &quote.ID,
+			CommentID:   &comment.ID,
+			GroupKey:    notificationGroup(models.NotificationQuoteComment, quote.ID),
+		})
+	}
+}
+
+// createComment проверяет текст фильтрами, сохраняет комментарий и отвечает 201.
+// published=false, если ответ с ошибкой уже отправлен или комментарий ушел на модерацию -
+// тогда о нем не нужно уведомлять
+func (h *CommentHandler) createComment(c *gin.Context, comment models.Comment) (*models.Comment, bool) {
+	content := contentfilter.Content{Kind: contentfilter.KindComment, Text: comment.Content, UserID: *comment.UserID, QuoteID: comment.QuoteID}
+	filtered, ok := filterContent(c, h.DB, h.Filter, content)
+	if !ok {
+		return nil, false
+	}
+
+	comment.Content = filtered.Text
+	if filtered.Queued() {
+		now := time.Now()
+		comment.HiddenAt = &now
 	}
 
 	if err := h.DB.Create(&comment).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
-		return
+		return nil, false
 	}
 
+	recordFilterResult(h.DB, content, filtered, comment.ID)
+
 	h.DB.Preload("User").First(&comment, comment.ID)
 	c.JSON(http.StatusCreated, comment)
+	if comment.HiddenAt != nil {
+		return &comment, false
+	}
+
+	h.Hub.Publish(realtime.EventCommentCreated, []string{realtime.QuoteTopic(comment.QuoteID)}, comment)
+	return &comment, true
 }
 
 // GetComments - получение комментариев для цитаты
@@ -71,23 +184,226 @@ func (h *CommentHandler) GetComments(c *gin.Context) {
 		return
 	}
 
-	// Проверяем существование цитаты
+	mode := c.DefaultQuery("mode", "flat")
+	params, errs := parseListParams(c, listOptions{})
+	if mode != "flat" && mode != "tree" && mode != "top" {
+		errs = append(errs, queryError{Field: "mode", Message: "must be flat, tree or top"})
+	}
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	// Проверяем существование цитаты; ветку скрытой цитаты видят только автор и модераторы
 	var quote models.Quote
-	if err := h.DB.First(&quote, quoteID).Error; err != nil {
+	if err := h.DB.First(&quote, quoteID).Error; err != nil || !canSeeQuote(c, &quote) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
 		return
 	}
 
-	var comments []models.Comment
-	if err := h.DB.Preload("User").
-		Where("quote_id = ?", quoteID).
-		Order("created_at DESC").
-		Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+	// Курсорная пагинация для flat и top: ?cursor= (пустое значение - первая страница)
+	if params.UseCursor && mode != "tree" {
+		query := h.DB.Model(&models.Comment{}).Scopes(visibleComments, withDeletedPlaceholders).Where("quote_id = ?", quoteID)
+		if mode == "top" {
+			query = query.Where("parent_id IS NULL")
+		}
+
+		page, err := fetchCursorPage(query.Scopes(withReplyCount).Preload("User"), commentCursorColumn, true, params.Cursor, params.Limit,
+			func(comment models.Comment) (interface{}, uint) { return comment.CreatedAt, comment.ID })
+		if err != nil {
+			if errors.Is(err, errInvalidCursor) {
+				respondQueryErrors(c, []queryError{{Field: "cursor", Message: "is invalid"}})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+			return
+		}
+		for i := range page.Items {
+			maskDeletedComment(&page.Items[i])
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"comments":   page.Items,
+			"pagination": page.Meta(params.Limit),
+		})
+		return
+	}
+
+	switch mode {
+	case "flat":
+		var comments []models.Comment
+		if err := h.DB.Scopes(withReplyCount, visibleComments, withDeletedPlaceholders).Preload("User").
+			Where("quote_id = ?", quoteID).
+			Order("created_at DESC").
+			Find(&comments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+			return
+		}
+		for i := range comments {
+			maskDeletedComment(&comments[i])
+		}
+
+		c.JSON(http.StatusOK, comments)
+
+	case "tree":
+		// Удаленные комментарии читаем тоже: заглушки нужны там, где в ветке остались ответы
+		var comments []*models.Comment
+		if err := h.DB.Unscoped().Scopes(withReplyCount).Preload("User").
+			Where("quote_id = ?", quoteID).
+			Order("created_at ASC").
+			Find(&comments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+			return
+		}
+
+		c.JSON(http.StatusOK, buildCommentTree(withoutDeletedLeaves(withoutHiddenBranches(comments))))
+
+	case "top":
+		query := h.DB.Model(&models.Comment{}).Scopes(visibleComments, withDeletedPlaceholders).Where("quote_id = ? AND parent_id IS NULL", quoteID)
+
+		var total int64
+		query.Count(&total)
+
+		var comments []models.Comment
+		if err := query.Scopes(withReplyCount).Preload("User").
+			Order("created_at DESC").
+			Offset(params.Offset()).Limit(params.Limit).
+			Find(&comments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+			return
+		}
+		for i := range comments {
+			maskDeletedComment(&comments[i])
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"comments":   comments,
+			"pagination": paginationMeta(params.Page, params.Limit, total),
+		})
+	}
+}
+
+// GetReplies - прямые ответы на комментарий
+func (h *CommentHandler) GetReplies(c *gin.Context) {
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var parent models.Comment
+	if err := h.DB.Scopes(visibleComments, withDeletedPlaceholders).First(&parent, commentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	var quote models.Quote
+	if err := h.DB.First(&quote, parent.QuoteID).Error; err != nil || !canSeeQuote(c, &quote) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	var replies []models.Comment
+	if err := h.DB.Scopes(withReplyCount, visibleComments, withDeletedPlaceholders).Preload("User").
+		Where("parent_id = ?", parent.ID).
+		Order("created_at ASC").
+		Find(&replies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch replies"})
+		return
+	}
+	for i := range replies {
+		maskDeletedComment(&replies[i])
+	}
+
+	c.JSON(http.StatusOK, replies)
+}
+
+// commentCursorColumn - комментарии листаются курсором от новых к старым
+var commentCursorColumn = cursorColumn{Expr: "comments.created_at", IDColumn: "comments.id", Kind: cursorKindTime}
+
+// withReplyCount добавляет к выборке количество прямых ответов (без удаленных)
+func withReplyCount(db *gorm.DB) *gorm.DB {
+	return db.Select("comments.*, (SELECT COUNT(*) FROM comments AS r WHERE r.parent_id = comments.id AND r.deleted_at IS NULL) AS reply_count")
+}
+
+// withDeletedPlaceholders оставляет в выборке удаленные комментарии, у которых есть
+// неудаленные ответы, - они показываются заглушкой (maskDeletedComment)
+func withDeletedPlaceholders(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("(comments.deleted_at IS NULL OR EXISTS (SELECT 1 FROM comments AS r WHERE r.parent_id = comments.id AND r.deleted_at IS NULL))")
+}
+
+// maskDeletedComment заменяет удаленный комментарий заглушкой "[deleted]" без автора
+func maskDeletedComment(comment *models.Comment) {
+	if !comment.DeletedAt.Valid {
 		return
 	}
+	comment.Content = models.DeletedCommentContent
+	comment.UserID = nil
+	comment.User = models.PublicUser{}
+	comment.IsDeleted = true
+}
 
-	c.JSON(http.StatusOK, comments)
+// withoutDeletedLeaves убирает удаленные комментарии, под которыми не осталось ответов,
+// а остальные удаленные превращает в заглушки. Проход идет с конца хронологического
+// списка, поэтому ответы обрабатываются раньше родителей.
+func withoutDeletedLeaves(comments []*models.Comment) []*models.Comment {
+	hasReplies := make(map[uint]bool)
+	kept := make([]*models.Comment, 0, len(comments))
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		if comment.DeletedAt.Valid && !hasReplies[comment.ID] {
+			continue
+		}
+		maskDeletedComment(comment)
+		kept = append(kept, comment)
+		if comment.ParentID != nil {
+			hasReplies[*comment.ParentID] = true
+		}
+	}
+
+	slices.Reverse(kept)
+	return kept
+}
+
+// withoutHiddenBranches убирает скрытые по жалобам комментарии вместе с ответами на них.
+// Комментарии идут в хронологическом порядке, поэтому родитель всегда встречается раньше ответов.
+func withoutHiddenBranches(comments []*models.Comment) []*models.Comment {
+	hidden := make(map[uint]bool)
+	visible := make([]*models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.HiddenAt != nil || (comment.ParentID != nil && hidden[*comment.ParentID]) {
+			hidden[comment.ID] = true
+			continue
+		}
+		visible = append(visible, comment)
+	}
+	return visible
+}
+
+// buildCommentTree раскладывает комментарии (в хронологическом порядке) по веткам.
+// Корневые комментарии возвращаются от новых к старым, ответы - по порядку.
+func buildCommentTree(comments []*models.Comment) []*models.Comment {
+	byID := make(map[uint]*models.Comment, len(comments))
+	for _, comment := range comments {
+		byID[comment.ID] = comment
+	}
+
+	roots := make([]*models.Comment, 0)
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			if parent, ok := byID[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, comment)
+				continue
+			}
+		}
+		roots = append(roots, comment)
+	}
+
+	for i, j := 0, len(roots)-1; i < j; i, j = i+1, j-1 {
+		roots[i], roots[j] = roots[j], roots[i]
+	}
+
+	return roots
 }
 
 // LikeComment - лайк комментария
@@ -109,12 +425,15 @@ func (h *CommentHandler) LikeComment(c *gin.Context) {
 	var existingLike models.CommentLike
 	result := h.DB.Where("comment_id = ? AND user_id = ?", commentID, userIDUint).First(&existingLike)
 
+	var comment models.Comment
+	liked := false
 	err = h.DB.Transaction(func(tx *gorm.DB) error {
-		var comment models.Comment
-		if err := tx.First(&comment, commentID).Error; err != nil {
+		// На скрытые по жалобам комментарии реагировать нельзя
+		if err := tx.Scopes(visibleComments).First(&comment, commentID).Error; err != nil {
 			return err
 		}
 
+		delta := 1
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			// Создаем новый лайк
 			like := models.CommentLike{
@@ -124,16 +443,27 @@ func (h *CommentHandler) LikeComment(c *gin.Context) {
 			if err := tx.Create(&like).Error; err != nil {
 				return err
 			}
-			comment.LikesCount++
+			liked = true
 		} else {
 			// Удаляем существующий лайк
 			if err := tx.Delete(&existingLike).Error; err != nil {
 				return err
 			}
-			comment.LikesCount--
+			delta = -1
 		}
 
-		return tx.Save(&comment).Error
+		// Меняем только счетчик: Save перезаписал бы hidden_at и deleted_at,
+		// выставленные параллельно жалобой или удалением
+		update := tx.Model(&comment).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "likes_count"}}}).
+			UpdateColumn("likes_count", gorm.Expr("likes_count + ?", delta))
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -145,6 +475,23 @@ func (h *CommentHandler) LikeComment(c *gin.Context) {
 		return
 	}
 
+	if liked {
+		notify(h.DB, models.NotificationEvent{
+			RecipientID: comment.UserID,
+			ActorID:     userIDUint,
+			Type:        models.NotificationCommentLike,
+			QuoteID:     &comment.QuoteID,
+			CommentID:   &comment.ID,
+			GroupKey:    notificationGroup(models.NotificationCommentLike, comment.ID),
+		})
+	}
+
+	h.Hub.Publish(realtime.EventCommentLiked, []string{realtime.QuoteTopic(comment.QuoteID)}, gin.H{
+		"comment_id":  comment.ID,
+		"quote_id":    comment.QuoteID,
+		"likes_count": comment.LikesCount,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "Like updated successfully"})
 }
 
@@ -170,13 +517,17 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Проверяем владельца
-	if comment.UserID == nil || *comment.UserID != userIDUint {
+	// Проверяем владельца (модераторы могут менять любой контент)
+	if !canModify(c, comment.UserID, userIDUint) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own comments"})
 		return
 	}
 
-	if err := h.DB.Delete(&comment).Error; err != nil {
+	// Комментарий уходит в корзину; если у него есть ответы, в ветке остается заглушка
+	if err := h.DB.Model(&comment).UpdateColumns(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": userIDUint,
+	}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
 		return
 	}
@@ -184,6 +535,50 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
 
+// RestoreComment - восстановление комментария из корзины
+func (h *CommentHandler) RestoreComment(c *gin.Context) {
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var comment models.Comment
+	if err := h.DB.Unscoped().First(&comment, commentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	if !canRestore(c, comment.UserID, comment.DeletedBy) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only restore comments you deleted yourself"})
+		return
+	}
+
+	if !comment.DeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comment is not deleted"})
+		return
+	}
+
+	// Комментарий удаленной цитаты все равно не будет виден
+	var quoteCount int64
+	h.DB.Model(&models.Quote{}).Where("id = ?", comment.QuoteID).Count(&quoteCount)
+	if quoteCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Restore the quote first"})
+		return
+	}
+
+	if err := h.DB.Unscoped().Model(&comment).UpdateColumns(map[string]interface{}{
+		"deleted_at": nil,
+		"deleted_by": nil,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore comment"})
+		return
+	}
+
+	h.DB.Preload("User").First(&comment, comment.ID)
+	c.JSON(http.StatusOK, comment)
+}
+
 // UpdateComment - обновление комментария
 func (h *CommentHandler) UpdateComment(c *gin.Context) {
 	commentID, err := strconv.Atoi(c.Param("id"))
@@ -206,8 +601,8 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	// Проверяем владельца
-	if comment.UserID == nil || *comment.UserID != userIDUint {
+	// Проверяем владельца (модераторы могут менять любой контент)
+	if !canModify(c, comment.UserID, userIDUint) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own comments"})
 		return
 	}
@@ -221,11 +616,23 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	comment.Content = input.Content
+	content := contentfilter.Content{Kind: contentfilter.KindComment, Text: input.Content, UserID: userIDUint, ExcludeID: comment.ID, QuoteID: comment.QuoteID}
+	filtered, ok := filterContent(c, h.DB, h.Filter, content)
+	if !ok {
+		return
+	}
+
+	comment.Content = filtered.Text
+	if filtered.Queued() {
+		now := time.Now()
+		comment.HiddenAt = &now
+	}
 	if err := h.DB.Save(&comment).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
 		return
 	}
 
+	recordFilterResult(h.DB, content, filtered, comment.ID)
+
 	c.JSON(http.StatusOK, comment)
 }
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"quotes-app/models"
+
+	"gorm.io/gorm"
+)
+
+// testComment - комментарий id с родителем parent (0 - корень); flags: "d" - удален, "h" - скрыт
+func testComment(id, parent uint, flags string) *models.Comment {
+	userID := id * 100
+	comment := &models.Comment{ID: id, Content: "text", UserID: &userID, User: models.PublicUser{ID: userID}}
+	if parent != 0 {
+		comment.ParentID = &parent
+	}
+	for _, flag := range flags {
+		switch flag {
+		case 'd':
+			comment.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		case 'h':
+			now := time.Now()
+			comment.HiddenAt = &now
+		}
+	}
+	return comment
+}
+
+func commentIDs(comments []*models.Comment) []uint {
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return ids
+}
+
+func TestWithoutDeletedLeaves(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []*models.Comment
+		want     []uint
+		masked   []uint
+	}{
+		{
+			name:     "nothing deleted",
+			comments: []*models.Comment{testComment(1, 0, ""), testComment(2, 1, "")},
+			want:     []uint{1, 2},
+		},
+		{
+			name:     "deleted leaf is dropped",
+			comments: []*models.Comment{testComment(1, 0, ""), testComment(2, 1, "d")},
+			want:     []uint{1},
+		},
+		{
+			name:     "deleted parent with live reply becomes placeholder",
+			comments: []*models.Comment{testComment(1, 0, "d"), testComment(2, 1, "")},
+			want:     []uint{1, 2},
+			masked:   []uint{1},
+		},
+		{
+			name: "chain of deleted comments without live replies is dropped",
+			comments: []*models.Comment{
+				testComment(1, 0, ""), testComment(2, 1, "d"), testComment(3, 2, "d"),
+			},
+			want: []uint{1},
+		},
+		{
+			name: "live reply deep in the branch keeps all deleted ancestors",
+			comments: []*models.Comment{
+				testComment(1, 0, "d"), testComment(2, 1, "d"), testComment(3, 2, ""), testComment(4, 1, "d"),
+			},
+			want:   []uint{1, 2, 3},
+			masked: []uint{1, 2},
+		},
+		{
+			name: "order is preserved",
+			comments: []*models.Comment{
+				testComment(1, 0, ""), testComment(2, 0, "d"), testComment(3, 1, ""), testComment(4, 2, ""),
+			},
+			want:   []uint{1, 2, 3, 4},
+			masked: []uint{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withoutDeletedLeaves(tt.comments)
+			if ids := commentIDs(got); !slices.Equal(ids, tt.want) {
+				t.Fatalf("withoutDeletedLeaves() = %v, want %v", ids, tt.want)
+			}
+			for _, comment := range got {
+				masked := slices.Contains(tt.masked, comment.ID)
+				if masked != (comment.Content == models.DeletedCommentContent) || masked != comment.IsDeleted {
+					t.Errorf("comment %d: content %q, is_deleted %v, want masked %v", comment.ID, comment.Content, comment.IsDeleted, masked)
+				}
+				if masked && (comment.UserID != nil || comment.User.ID != 0) {
+					t.Errorf("comment %d: placeholder keeps author %v", comment.ID, comment.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentTreeWithHiddenAndDeleted(t *testing.T) {
+	// Скрытая ветка убирается целиком, поэтому удаленный родитель ее ответов
+	// остается без живых ответов и тоже пропадает
+	comments := []*models.Comment{
+		testComment(1, 0, ""),
+		testComment(2, 0, "d"),
+		testComment(3, 2, "h"),
+		testComment(4, 3, ""),
+		testComment(5, 1, "d"),
+		testComment(6, 5, ""),
+	}
+
+	roots := buildCommentTree(withoutDeletedLeaves(withoutHiddenBranches(comments)))
+	if ids := commentIDs(roots); !slices.Equal(ids, []uint{1}) {
+		t.Fatalf("roots = %v, want [1]", ids)
+	}
+	if ids := commentIDs(roots[0].Replies); !slices.Equal(ids, []uint{5}) {
+		t.Fatalf("replies of 1 = %v, want [5]", ids)
+	}
+	placeholder := roots[0].Replies[0]
+	if !placeholder.IsDeleted || placeholder.Content != models.DeletedCommentContent {
+		t.Errorf("comment 5 = %+v, want placeholder", placeholder)
+	}
+	if ids := commentIDs(placeholder.Replies); !slices.Equal(ids, []uint{6}) {
+		t.Errorf("replies of 5 = %v, want [6]", ids)
+	}
+}
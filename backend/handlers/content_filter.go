@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"quotes-app/contentfilter"
+	"quotes-app/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// filterContent прогоняет текст через фильтры. Отклоненный текст пишется в журнал,
+// а клиент получает 400; ok=false, если ответ уже отправлен
+func filterContent(c *gin.Context, db *gorm.DB, pipeline *contentfilter.Pipeline, content contentfilter.Content) (contentfilter.Result, bool) {
+	result := pipeline.Apply(content)
+	if !result.Rejected() {
+		return result, true
+	}
+
+	recordFilterResult(db, content, result, 0)
+
+	rejection := result.Rejection()
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "Content rejected by filter",
+		"filter": rejection.Filter,
+		"reason": rejection.Reason,
+	})
+	return result, false
+}
+
+// recordFilterResult пишет сработавшие фильтры в журнал. Если текст ушел на модерацию,
+// объект targetID (уже сохраненный скрытым) попадает в очередь жалоб от имени системы.
+// Ошибки только логируются: контент к этому моменту уже сохранен.
+func recordFilterResult(db *gorm.DB, content contentfilter.Content, result contentfilter.Result, targetID uint) {
+	if len(result.Decisions) == 0 {
+		return
+	}
+
+	var userID, quoteID, commentID *uint
+	if content.UserID != 0 {
+		userID = &content.UserID
+	}
+	if targetID != 0 {
+		if content.Kind == contentfilter.KindComment {
+			commentID = &targetID
+		} else {
+			quoteID = &targetID
+		}
+	}
+
+	entries := make([]models.ContentFilterLog, 0, len(result.Decisions))
+	var reports []models.Report
+	for _, decision := range result.Decisions {
+		entry := models.ContentFilterLog{
+			UserID:     userID,
+			TargetType: content.Kind,
+			QuoteID:    quoteID,
+			CommentID:  commentID,
+			Filter:     decision.Filter,
+			Action:     string(decision.Action),
+			Reason:     decision.Reason,
+			Content:    content.Text,
+		}
+		if len(decision.Terms) > 0 {
+			terms := strings.Join(decision.Terms, ", ")
+			entry.Terms = &terms
+		}
+		entries = append(entries, entry)
+
+		if decision.Action == contentfilter.ActionQueue && targetID != 0 {
+			details := "Content filter " + decision.Filter + ": " + decision.Reason
+			reports = append(reports, models.Report{
+				TargetType: content.Kind,
+				QuoteID:    quoteID,
+				CommentID:  commentID,
+				Reason:     decision.Category,
+				Details:    &details,
+			})
+		}
+	}
+
+	if err := db.Create(&entries).Error; err != nil {
+		log.Printf("Failed to write content filter log: %v", err)
+	}
+	if len(reports) > 0 {
+		if err := db.Create(&reports).Error; err != nil {
+			log.Printf("Failed to queue %s %d for moderation: %v", content.Kind, targetID, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Типы значений колонки сортировки, которые можно положить в курсор
+const (
+	cursorKindTime   = "time"
+	cursorKindInt    = "int"
+	cursorKindString = "string"
+)
+
+// cursorColumn описывает сортировку для keyset-пагинации: ключ сортировки + id как tie-breaker
+type cursorColumn struct {
+	Expr     string
+	IDColumn string
+	Kind     string
+}
+
+// cursor - содержимое непрозрачного токена next_cursor/prev_cursor.
+// Sort - сортировка, для которой выдан курсор: с другой сортировкой значение
+// относится к другой колонке, и такой курсор отклоняется
+type cursor struct {
+	Sort     string          `json:"s"`
+	Value    json.RawMessage `json:"v"`
+	ID       uint            `json:"id"`
+	Backward bool            `json:"b,omitempty"`
+}
+
+// sortKey - сортировка по колонке в заданном направлении, записывается в курсор
+func (col cursorColumn) sortKey(desc bool) string {
+	if desc {
+		return col.Expr + " DESC"
+	}
+	return col.Expr + " ASC"
+}
+
+// cursorPage - страница результата и курсоры на соседние страницы
+type cursorPage[T any] struct {
+	Items      []T
+	NextCursor *string
+	PrevCursor *string
+}
+
+// Meta - блок pagination для ответов в режиме курсоров
+func (p *cursorPage[T]) Meta(limit int) gin.H {
+	return gin.H{
+		"limit":       limit,
+		"next_cursor": p.NextCursor,
+		"prev_cursor": p.PrevCursor,
+	}
+}
+
+// fetchCursorPage выбирает страницу после (или до) курсора без OFFSET и COUNT.
+// key возвращает значение ключа сортировки и id элемента для построения курсоров.
+func fetchCursorPage[T any](query *gorm.DB, col cursorColumn, desc bool, rawCursor string, limit int,
+	key func(T) (interface{}, uint)) (*cursorPage[T], error) {
+	sort := col.sortKey(desc)
+	var cur *cursor
+	if rawCursor != "" {
+		decoded, err := decodeCursor(rawCursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		cur = decoded
+	}
+
+	backward := cur != nil && cur.Backward
+	scanDesc := desc != backward
+
+	if cur != nil {
+		value, err := col.parseValue(cur.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		op := ">"
+		if scanDesc {
+			op = "<"
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", col.Expr, op, col.Expr, col.IDColumn, op),
+			value, value, cur.ID,
+		)
+	}
+
+	direction := "ASC"
+	if scanDesc {
+		direction = "DESC"
+	}
+
+	var items []T
+	if err := query.
+		Order(col.Expr + " " + direction).
+		Order(col.IDColumn + " " + direction).
+		Limit(limit + 1).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	page := &cursorPage[T]{Items: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	// Вперед можно идти, если при прямом проходе есть еще строки или мы пришли назад;
+	// назад - если при обратном проходе есть еще строки или мы пришли по курсору вперед
+	if backward || hasMore {
+		value, id := key(items[len(items)-1])
+		next, err := encodeCursor(sort, value, id, false)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = &next
+	}
+	if (backward && hasMore) || (!backward && cur != nil) {
+		value, id := key(items[0])
+		prev, err := encodeCursor(sort, value, id, true)
+		if err != nil {
+			return nil, err
+		}
+		page.PrevCursor = &prev
+	}
+
+	return page, nil
+}
+
+func encodeCursor(sort string, value interface{}, id uint, backward bool) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(cursor{Sort: sort, Value: raw, ID: id, Backward: backward})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor разбирает токен и проверяет, что он выдан для сортировки sort
+func decodeCursor(s, sort string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.Value == nil || cur.Sort != sort {
+		return nil, errInvalidCursor
+	}
+
+	return &cur, nil
+}
+
+// parseValue восстанавливает типизированное значение ключа сортировки из курсора
+func (col cursorColumn) parseValue(raw json.RawMessage) (interface{}, error) {
+	switch col.Kind {
+	case cursorKindTime:
+		var t time.Time
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, errInvalidCursor
+		}
+		return t, nil
+	case cursorKindInt:
+		var n int64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, errInvalidCursor
+		}
+		return n, nil
+	default:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, errInvalidCursor
+		}
+		return s, nil
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorSortMismatch(t *testing.T) {
+	byDate := cursorColumn{Expr: "quotes.created_at", IDColumn: "quotes.id", Kind: cursorKindTime}
+	byLikes := cursorColumn{Expr: "quotes.likes_count", IDColumn: "quotes.id", Kind: cursorKindInt}
+
+	token, err := encodeCursor(byLikes.sortKey(true), 17, 42, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cur, err := decodeCursor(token, byLikes.sortKey(true))
+	if err != nil {
+		t.Fatalf("decodeCursor() with the same sort error = %v", err)
+	}
+	if cur.ID != 42 {
+		t.Errorf("ID = %d, want 42", cur.ID)
+	}
+
+	for _, sort := range []string{byLikes.sortKey(false), byDate.sortKey(true), ""} {
+		if _, err := decodeCursor(token, sort); !errors.Is(err, errInvalidCursor) {
+			t.Errorf("decodeCursor() with sort %q error = %v, want errInvalidCursor", sort, err)
+		}
+	}
+
+	dateToken, err := encodeCursor(byDate.sortKey(true), time.Now(), 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeCursor(dateToken, byDate.sortKey(true)); err != nil {
+		t.Errorf("decodeCursor() error = %v", err)
+	}
+	if _, err := decodeCursor("not base64!", byDate.sortKey(true)); !errors.Is(err, errInvalidCursor) {
+		t.Errorf("decodeCursor() of garbage error = %v, want errInvalidCursor", err)
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errNoQuotesAvailable = errors.New("no quotes available")
+
+type DailyQuoteHandler struct {
+	DB         *gorm.DB
+	Location   *time.Location
+	RepeatDays int
+	PoolSize   int
+}
+
+func NewDailyQuoteHandler() *DailyQuoteHandler {
+	return &DailyQuoteHandler{
+		DB:         config.DB,
+		Location:   config.DailyQuoteLocation,
+		RepeatDays: config.DailyQuoteRepeatDays,
+		PoolSize:   config.DailyQuotePoolSize,
+	}
+}
+
+// GetDailyQuote - цитата дня, одинаковая для всех в течение календарного дня
+func (h *DailyQuoteHandler) GetDailyQuote(c *gin.Context) {
+	var errs []queryError
+	categoryID, hasCategory := parseOptionalID(c, "category_id", &errs)
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var category *uint
+	if hasCategory {
+		var count int64
+		h.DB.Model(&models.Category{}).Where("id = ?", categoryID).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		category = &categoryID
+	}
+
+	date := time.Now().In(h.Location).Format(time.DateOnly)
+
+	daily, err := h.findDaily(date, category)
+	if err == nil && (daily.Quote.ID == 0 || daily.Quote.HiddenAt != nil) {
+		// Цитату дня удалили в корзину или скрыли по жалобам - выбираем другую до конца дня
+		err = h.DB.Delete(&models.DailyQuote{}, daily.ID).Error
+		if err == nil {
+			err = gorm.ErrRecordNotFound
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		daily, err = h.pickDaily(date, category)
+	}
+	if err != nil {
+		if errors.Is(err, errNoQuotesAvailable) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No quotes available"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"date":        date,
+		"timezone":    h.Location.String(),
+		"category_id": category,
+		"quote":       daily.Quote,
+	})
+}
+
+// findDaily ищет уже выбранную цитату дня для ленты
+func (h *DailyQuoteHandler) findDaily(date string, category *uint) (*models.DailyQuote, error) {
+	var daily models.DailyQuote
+	err := dailyScope(h.DB, category).
+		Preload("Quote").Preload("Quote.User").Preload("Quote.Category").Preload("Quote.Tags").
+		Where("quote_date = CAST(? AS DATE)", date).
+		First(&daily).Error
+	if err != nil {
+		return nil, err
+	}
+	return &daily, nil
+}
+
+// pickDaily выбирает цитату дня и сохраняет ее в истории.
+// Выбор детерминирован по дате и ленте, поэтому параллельные запросы
+// выбирают одно и то же, а запись выполняется через ON CONFLICT DO NOTHING.
+func (h *DailyQuoteHandler) pickDaily(date string, category *uint) (*models.DailyQuote, error) {
+	candidates, err := h.candidates(date, category, true)
+	if err != nil {
+		return nil, err
+	}
+	// Все цитаты ленты уже показывались в окне повторов - разрешаем повтор
+	if len(candidates) == 0 {
+		candidates, err = h.candidates(date, category, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errNoQuotesAvailable
+	}
+
+	quoteID := weightedPick(candidates, dailySeed(date, category))
+
+	if err := h.DB.Exec(`INSERT INTO daily_quotes (quote_date, category_id, quote_id)
+		VALUES (CAST(? AS DATE), ?, ?)
+		ON CONFLICT (quote_date, (COALESCE(category_id, 0))) DO NOTHING`,
+		date, category, quoteID).Error; err != nil {
+		return nil, err
+	}
+
+	return h.findDaily(date, category)
+}
+
+// candidates - лучшие по рейтингу цитаты ленты, при excludeRecent без недавних цитат дня
+func (h *DailyQuoteHandler) candidates(date string, category *uint, excludeRecent bool) ([]scoredQuote, error) {
+	query := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).
+		Select("quotes.id, quotes.likes_count - quotes.dislikes_count AS score")
+
+	if category != nil {
+		query = query.Where("quotes.category_id = ?", *category)
+	}
+
+	if excludeRecent {
+		recent := dailyScope(h.DB.Model(&models.DailyQuote{}), category).
+			Select("quote_id").
+			Where("quote_date > CAST(? AS DATE) - CAST(? AS INTEGER)", date, h.RepeatDays)
+		query = query.Where("quotes.id NOT IN (?)", recent)
+	}
+
+	var candidates []scoredQuote
+	err := query.
+		Order("score DESC").Order("quotes.id ASC").
+		Limit(h.PoolSize).
+		Scan(&candidates).Error
+	return candidates, err
+}
+
+// dailyScope ограничивает запрос записями ленты: всех цитат или одной категории
+func dailyScope(db *gorm.DB, category *uint) *gorm.DB {
+	if category == nil {
+		return db.Where("daily_quotes.category_id IS NULL")
+	}
+	return db.Where("daily_quotes.category_id = ?", *category)
+}
+
+// dailySeed - стабильное псевдослучайное число для даты и ленты
+func dailySeed(date string, category *uint) uint64 {
+	scope := "all"
+	if category != nil {
+		scope = "category:" + strconv.FormatUint(uint64(*category), 10)
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(date + "|" + scope))
+	return hash.Sum64()
+}
+
+// weightedPick детерминированно выбирает цитату с учетом веса по рейтингу
+func weightedPick(candidates []scoredQuote, seed uint64) uint {
+	var total uint64
+	for _, candidate := range candidates {
+		total += uint64(candidate.Weight())
+	}
+
+	target := seed % total
+	for _, candidate := range candidates {
+		weight := uint64(candidate.Weight())
+		if target < weight {
+			return candidate.ID
+		}
+		target -= weight
+	}
+
+	return candidates[len(candidates)-1].ID
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxDuplicateMatches - сколько похожих цитат показывать при создании
+const maxDuplicateMatches = 5
+
+// maxDuplicatePairs - ограничение на число пар при построении кластеров
+const maxDuplicatePairs = 5000
+
+// duplicateMatch - существующая цитата, похожая на новую
+type duplicateMatch struct {
+	ID         uint    `json:"id"`
+	Content    string  `json:"content"`
+	Author     string  `json:"author"`
+	Similarity float64 `json:"similarity"`
+	Exact      bool    `json:"exact"`
+}
+
+// findDuplicateQuotes ищет цитаты, совпадающие с content после нормализации или похожие
+// не меньше чем на threshold. Оператор % отбирает кандидатов по GIN-индексу
+// (с порогом pg_trgm.similarity_threshold), точный порог проверяется отдельно.
+// Скрытые по жалобам цитаты не учитываются: автор не смог бы их открыть
+func findDuplicateQuotes(db *gorm.DB, content string, threshold float64) ([]duplicateMatch, error) {
+	var matches []duplicateMatch
+	err := db.Raw(`SELECT id, content, author, similarity, exact FROM (
+		SELECT quotes.id, quotes.content, quotes.author,
+			similarity(quotes.content_normalized, n.value) AS similarity,
+			quotes.content_normalized = n.value AS exact
+		FROM quotes, (SELECT normalize_quote_content(CAST(@content AS TEXT)) AS value) n
+		WHERE (quotes.content_normalized = n.value OR quotes.content_normalized % n.value)
+			AND quotes.deleted_at IS NULL AND quotes.hidden_at IS NULL
+	) candidates
+	WHERE exact OR similarity >= @threshold
+	ORDER BY exact DESC, similarity DESC, id ASC
+	LIMIT @limit`,
+		map[string]interface{}{"content": content, "threshold": threshold, "limit": maxDuplicateMatches}).
+		Scan(&matches).Error
+	return matches, err
+}
+
+type DuplicateHandler struct {
+	DB        *gorm.DB
+	Threshold float64
+}
+
+func NewDuplicateHandler() *DuplicateHandler {
+	return &DuplicateHandler{DB: config.DB, Threshold: config.QuoteDuplicateSimilarity}
+}
+
+// duplicatePair - две похожие цитаты
+type duplicatePair struct {
+	QuoteID     uint
+	DuplicateID uint
+	Similarity  float64
+}
+
+// duplicateCluster - группа цитат, связанных похожестью; первой идет самая популярная
+type duplicateCluster struct {
+	QuoteIDs      []uint         `json:"quote_ids"`
+	MaxSimilarity float64        `json:"max_similarity"`
+	Quotes        []models.Quote `json:"quotes"`
+}
+
+// GetDuplicates - кластеры похожих цитат для объединения; similarity - порог в процентах
+func (h *DuplicateHandler) GetDuplicates(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+	threshold := h.Threshold
+	if raw := c.Query("similarity"); raw != "" {
+		percent, err := strconv.Atoi(raw)
+		if err != nil || percent < 30 || percent > 100 {
+			errs = append(errs, queryError{Field: "similarity", Message: "must be an integer from 30 to 100"})
+		} else {
+			threshold = float64(percent) / 100
+		}
+	}
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var pairs []duplicatePair
+	if err := h.DB.Raw(`SELECT a.id AS quote_id, b.id AS duplicate_id,
+			similarity(a.content_normalized, b.content_normalized) AS similarity
+		FROM quotes a
+		JOIN quotes b ON b.id > a.id
+			AND (b.content_normalized = a.content_normalized OR b.content_normalized % a.content_normalized)
+			AND b.deleted_at IS NULL
+		WHERE a.deleted_at IS NULL
+			AND similarity(a.content_normalized, b.content_normalized) >= ?
+		ORDER BY similarity DESC
+		LIMIT ?`, threshold, maxDuplicatePairs).
+		Scan(&pairs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find duplicates"})
+		return
+	}
+
+	clusters := clusterDuplicates(pairs)
+	total := int64(len(clusters))
+	start := min(params.Offset(), len(clusters))
+	clusters = clusters[start:min(start+params.Limit, len(clusters))]
+
+	// Загружаем цитаты только для текущей страницы кластеров
+	var ids []uint
+	for _, cluster := range clusters {
+		ids = append(ids, cluster.QuoteIDs...)
+	}
+	var quotes []models.Quote
+	if len(ids) > 0 {
+		if err := h.DB.Preload("User").Preload("Category").Where("id IN ?", ids).Find(&quotes).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find duplicates"})
+			return
+		}
+	}
+	byID := make(map[uint]models.Quote, len(quotes))
+	for _, quote := range quotes {
+		byID[quote.ID] = quote
+	}
+
+	for i := range clusters {
+		cluster := &clusters[i]
+		for _, id := range cluster.QuoteIDs {
+			if quote, ok := byID[id]; ok {
+				cluster.Quotes = append(cluster.Quotes, quote)
+			}
+		}
+		slices.SortStableFunc(cluster.Quotes, func(a, b models.Quote) int {
+			if score := (b.LikesCount - b.DislikesCount) - (a.LikesCount - a.DislikesCount); score != 0 {
+				return score
+			}
+			return int(a.ID) - int(b.ID)
+		})
+		for j, quote := range cluster.Quotes {
+			cluster.QuoteIDs[j] = quote.ID
+		}
+		cluster.QuoteIDs = cluster.QuoteIDs[:len(cluster.Quotes)]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"clusters":   clusters,
+		"similarity": threshold,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// clusterDuplicates объединяет пары в связные группы (union-find);
+// крупные кластеры идут первыми
+func clusterDuplicates(pairs []duplicatePair) []duplicateCluster {
+	parent := make(map[uint]uint)
+	var find func(id uint) uint
+	find = func(id uint) uint {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	for _, pair := range pairs {
+		a, b := find(pair.QuoteID), find(pair.DuplicateID)
+		if a != b {
+			parent[max(a, b)] = min(a, b)
+		}
+	}
+
+	index := make(map[uint]int)
+	var clusters []duplicateCluster
+	for _, pair := range pairs {
+		root := find(pair.QuoteID)
+		i, ok := index[root]
+		if !ok {
+			i = len(clusters)
+			index[root] = i
+			clusters = append(clusters, duplicateCluster{})
+		}
+		cluster := &clusters[i]
+		for _, id := range []uint{pair.QuoteID, pair.DuplicateID} {
+			if !slices.Contains(cluster.QuoteIDs, id) {
+				cluster.QuoteIDs = append(cluster.QuoteIDs, id)
+			}
+		}
+		cluster.MaxSimilarity = max(cluster.MaxSimilarity, pair.Similarity)
+	}
+
+	slices.SortStableFunc(clusters, func(a, b duplicateCluster) int {
+		if len(a.QuoteIDs) != len(b.QuoteIDs) {
+			return len(b.QuoteIDs) - len(a.QuoteIDs)
+		}
+		if a.MaxSimilarity > b.MaxSimilarity {
+			return -1
+		}
+		if a.MaxSimilarity < b.MaxSimilarity {
+			return 1
+		}
+		return 0
+	})
+	return clusters
+}
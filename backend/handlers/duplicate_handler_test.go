@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClusterDuplicates(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs []duplicatePair
+		want  []duplicateCluster
+	}{
+		{
+			name:  "no pairs",
+			pairs: nil,
+			want:  nil,
+		},
+		{
+			name:  "single pair",
+			pairs: []duplicatePair{{QuoteID: 1, DuplicateID: 2, Similarity: 0.9}},
+			want:  []duplicateCluster{{QuoteIDs: []uint{1, 2}, MaxSimilarity: 0.9}},
+		},
+		{
+			name: "chain is merged transitively",
+			pairs: []duplicatePair{
+				{QuoteID: 1, DuplicateID: 2, Similarity: 0.85},
+				{QuoteID: 2, DuplicateID: 3, Similarity: 0.95},
+			},
+			want: []duplicateCluster{{QuoteIDs: []uint{1, 2, 3}, MaxSimilarity: 0.95}},
+		},
+		{
+			name: "pairs joined late merge two groups",
+			pairs: []duplicatePair{
+				{QuoteID: 1, DuplicateID: 2, Similarity: 0.9},
+				{QuoteID: 5, DuplicateID: 6, Similarity: 0.8},
+				{QuoteID: 2, DuplicateID: 6, Similarity: 0.82},
+			},
+			want: []duplicateCluster{{QuoteIDs: []uint{1, 2, 5, 6}, MaxSimilarity: 0.9}},
+		},
+		{
+			name: "larger clusters first, then by similarity",
+			pairs: []duplicatePair{
+				{QuoteID: 10, DuplicateID: 11, Similarity: 0.99},
+				{QuoteID: 20, DuplicateID: 21, Similarity: 0.8},
+				{QuoteID: 20, DuplicateID: 22, Similarity: 0.8},
+				{QuoteID: 30, DuplicateID: 31, Similarity: 1},
+			},
+			want: []duplicateCluster{
+				{QuoteIDs: []uint{20, 21, 22}, MaxSimilarity: 0.8},
+				{QuoteIDs: []uint{30, 31}, MaxSimilarity: 1},
+				{QuoteIDs: []uint{10, 11}, MaxSimilarity: 0.99},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clusterDuplicates(tt.pairs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("clusterDuplicates() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				ids := slices.Clone(got[i].QuoteIDs)
+				slices.Sort(ids)
+				if !slices.Equal(ids, tt.want[i].QuoteIDs) || got[i].MaxSimilarity != tt.want[i].MaxSimilarity {
+					t.Errorf("cluster %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
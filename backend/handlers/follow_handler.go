@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxFeedLimit - лента читает до limit цитат из каждого источника, поэтому лимит меньше обычного
+const maxFeedLimit = 50
+
+// feedCursorColumn - лента всегда идет от новых к старым
+var feedCursorColumn = cursorColumn{Expr: "quotes.created_at", IDColumn: "quotes.id", Kind: cursorKindTime}
+
+type FollowHandler struct {
+	DB *gorm.DB
+}
+
+func NewFollowHandler() *FollowHandler {
+	return &FollowHandler{DB: config.DB}
+}
+
+// FollowUser - подписка на пользователя (повторная подписка ничего не меняет)
+func (h *FollowHandler) FollowUser(c *gin.Context) {
+	followeeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if uint(followeeID) == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return
+	}
+
+	var followee models.PublicUser
+	if err := h.DB.First(&followee, followeeID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	follow := models.UserFollow{FollowerID: userID, FolloweeID: followee.ID}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User followed successfully"})
+}
+
+// UnfollowUser - отписка от пользователя
+func (h *FollowHandler) UnfollowUser(c *gin.Context) {
+	followeeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.DB.Where("follower_id = ? AND followee_id = ?", c.GetUint("user_id"), followeeID).
+		Delete(&models.UserFollow{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed successfully"})
+}
+
+// GetFollowers - подписчики пользователя, новые первыми
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	h.listFollowUsers(c, "user_follows.follower_id", "user_follows.followee_id")
+}
+
+// GetFollowing - на кого подписан пользователь, новые подписки первыми
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	h.listFollowUsers(c, "user_follows.followee_id", "user_follows.follower_id")
+}
+
+// listFollowUsers выбирает пользователей по userColumn для строк, где ownerColumn = :id
+func (h *FollowHandler) listFollowUsers(c *gin.Context, userColumn, ownerColumn string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var user models.PublicUser
+	if err := h.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	query := h.DB.Model(&models.PublicUser{}).
+		Joins("JOIN user_follows ON "+userColumn+" = users.id").
+		Where(ownerColumn+" = ?", user.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	var users []models.PublicUser
+	if err := query.Order("user_follows.created_at DESC, users.id DESC").
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":      users,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// FollowCategory - подписка на категорию
+func (h *FollowHandler) FollowCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var category models.Category
+	if err := h.DB.First(&category, categoryID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	follow := models.CategoryFollow{UserID: c.GetUint("user_id"), CategoryID: category.ID}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category followed successfully"})
+}
+
+// UnfollowCategory - отписка от категории
+func (h *FollowHandler) UnfollowCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	if err := h.DB.Where("user_id = ? AND category_id = ?", c.GetUint("user_id"), categoryID).
+		Delete(&models.CategoryFollow{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category unfollowed successfully"})
+}
+
+// GetFollowedCategories - категории, на которые подписан текущий пользователь
+func (h *FollowHandler) GetFollowedCategories(c *gin.Context) {
+	var follows []models.CategoryFollow
+	if err := h.DB.Preload("Category").
+		Where("user_id = ?", c.GetUint("user_id")).
+		Order("created_at DESC").
+		Find(&follows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": follows})
+}
+
+// feedItem - ключ ленты: цитата и ее время создания для курсора
+type feedItem struct {
+	ID        uint
+	CreatedAt time.Time
+}
+
+// GetFeed - цитаты пользователей и категорий из подписок, от новых к старым.
+// Пагинация только курсором: ?cursor= из next_cursor предыдущей страницы.
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+	if params.Limit > maxFeedLimit {
+		errs = append(errs, queryError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxFeedLimit)})
+	}
+	if _, hasPage := c.GetQuery("page"); hasPage && !params.UseCursor {
+		errs = append(errs, queryError{Field: "page", Message: "is not supported, use cursor"})
+	}
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	items, err := h.feedPage(c.GetUint("user_id"), params.Cursor, params.Limit+1)
+	if err != nil {
+		if errors.Is(err, errInvalidCursor) {
+			respondQueryErrors(c, []queryError{{Field: "cursor", Message: "is invalid"}})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+		return
+	}
+
+	page := &cursorPage[models.Quote]{Items: []models.Quote{}}
+	if len(items) > params.Limit {
+		items = items[:params.Limit]
+		last := items[len(items)-1]
+		next, err := encodeCursor(feedCursorColumn.sortKey(true), last.CreatedAt, last.ID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+			return
+		}
+		page.NextCursor = &next
+	}
+
+	if len(items) > 0 {
+		ids := make([]uint, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+
+		if err := h.DB.Preload("User").Preload("Category").Preload("Tags").
+			Where("id IN ?", ids).
+			Find(&page.Items).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+			return
+		}
+
+		order := make(map[uint]int, len(ids))
+		for i, id := range ids {
+			order[id] = i
+		}
+		slices.SortFunc(page.Items, func(a, b models.Quote) int { return order[a.ID] - order[b.ID] })
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotes":     page.Items,
+		"pagination": page.Meta(params.Limit),
+	})
+}
+
+// feedPage выбирает ключи следующей страницы ленты.
+// Для каждой подписки через LATERAL читается не больше limit последних цитат по индексу
+// (user_id|category_id, created_at, id), затем результаты сливаются. Стоимость страницы
+// растет с числом подписок линейно, а не с размером таблицы цитат.
+func (h *FollowHandler) feedPage(userID uint, rawCursor string, limit int) ([]feedItem, error) {
+	keyset := ""
+	args := map[string]interface{}{"user": userID, "limit": limit}
+
+	if rawCursor != "" {
+		cur, err := decodeCursor(rawCursor, feedCursorColumn.sortKey(true))
+		if err != nil {
+			return nil, err
+		}
+		value, err := feedCursorColumn.parseValue(cur.Value)
+		if err != nil {
+			return nil, err
+		}
+		keyset = "AND (quotes.created_at, quotes.id) < (@cursor_time, @cursor_id)"
+		args["cursor_time"] = value
+		args["cursor_id"] = cur.ID
+	}
+
+	sql := `SELECT id, created_at FROM (
+		SELECT q.id, q.created_at
+		FROM user_follows
+		CROSS JOIN LATERAL (
+			SELECT quotes.id, quotes.created_at FROM quotes
+			WHERE quotes.user_id = user_follows.followee_id
+				AND quotes.hidden_at IS NULL AND quotes.deleted_at IS NULL ` + keyset + `
+			ORDER BY quotes.created_at DESC, quotes.id DESC
+			LIMIT @limit
+		) q
+		WHERE user_follows.follower_id = @user
+		UNION
+		SELECT q.id, q.created_at
+		FROM category_follows
+		CROSS JOIN LATERAL (
+			SELECT quotes.id, quotes.created_at FROM quotes
+			WHERE quotes.category_id = category_follows.category_id
+				AND quotes.user_id IS DISTINCT FROM @user
+				AND quotes.hidden_at IS NULL AND quotes.deleted_at IS NULL ` + keyset + `
+			ORDER BY quotes.created_at DESC, quotes.id DESC
+			LIMIT @limit
+		) q
+		WHERE category_follows.user_id = @user
+	) feed
+	ORDER BY created_at DESC, id DESC
+	LIMIT @limit`
+
+	var items []feedItem
+	err := h.DB.Raw(sql, args).Scan(&items).Error
+	return items, err
+}
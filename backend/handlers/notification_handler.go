@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationHandler struct {
+	DB *gorm.DB
+}
+
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{DB: config.DB}
+}
+
+// GetNotifications - уведомления текущего пользователя, недавно обновленные первыми
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+
+	unreadOnly := false
+	if raw, ok := c.GetQuery("unread"); ok {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, queryError{Field: "unread", Message: "must be true or false"})
+		}
+		unreadOnly = b
+	}
+
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	query := h.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	var notifications []models.Notification
+	if err := query.Preload("LastActor").
+		Order("updated_at DESC, id DESC").
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	for i := range notifications {
+		notifications[i].Message = notificationMessage(&notifications[i])
+	}
+
+	unread, err := h.unreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"pagination":    paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// GetUnreadCount - число непрочитанных уведомлений (для бейджа)
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	unread, err := h.unreadCount(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkRead - отметить прочитанными переданные уведомления или все (all=true)
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	var input models.NotificationReadRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !input.All && len(input.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide ids or all=true"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	query := h.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if !input.All {
+		query = query.Where("id IN ?", input.IDs)
+	}
+
+	result := query.Update("read_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	unread, err := h.unreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"marked":       result.RowsAffected,
+		"unread_count": unread,
+	})
+}
+
+// GetPreferences - включенные и отключенные типы уведомлений
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.preferences(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences - включение/отключение типов: {"quote_like": false}
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var input map[string]bool
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	rows := make([]models.NotificationPreference, 0, len(input))
+	for notificationType, enabled := range input {
+		if !slices.Contains(models.NotificationTypes, notificationType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown notification type: " + notificationType})
+			return
+		}
+		rows = append(rows, models.NotificationPreference{UserID: userID, Type: notificationType, Enabled: enabled})
+	}
+
+	if len(rows) > 0 {
+		if err := h.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+		}).Create(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update preferences"})
+			return
+		}
+	}
+
+	preferences, err := h.preferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) unreadCount(userID uint) (int64, error) {
+	var count int64
+	err := h.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// preferences - все типы уведомлений; без сохраненной настройки тип включен
+func (h *NotificationHandler) preferences(userID uint) (map[string]bool, error) {
+	var rows []models.NotificationPreference
+	if err := h.DB.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, row := range rows {
+		preferences[row.Type] = row.Enabled
+	}
+	return preferences, nil
+}
+
+// notificationMessage - текст уведомления с учетом агрегации: "user2 and 4 others liked your quote"
+func notificationMessage(n *models.Notification) string {
+	actor := "Someone"
+	if n.LastActor != nil {
+		actor = n.LastActor.Username
+	}
+	if n.ActorCount > 1 {
+		others := "others"
+		if n.ActorCount == 2 {
+			others = "other"
+		}
+		actor = fmt.Sprintf("%s and %d %s", actor, n.ActorCount-1, others)
+	}
+
+	switch n.Type {
+	case models.NotificationQuoteLike:
+		return actor + " liked your quote"
+	case models.NotificationQuoteComment:
+		return actor + " commented on your quote"
+	case models.NotificationCommentReply:
+		return actor + " replied to your comment"
+	case models.NotificationCommentLike:
+		return actor + " liked your comment"
+	default:
+		return actor + " interacted with your content"
+	}
+}
+
+// notificationGroup - ключ агрегации: события одного типа по одному объекту
+func notificationGroup(notificationType string, objectID uint) string {
+	return notificationType + ":" + strconv.FormatUint(uint64(objectID), 10)
+}
+
+// notify создает или дополняет уведомление. Ошибки только логируются:
+// уведомления не должны ломать основное действие пользователя.
+func notify(db *gorm.DB, event models.NotificationEvent) {
+	if event.RecipientID == nil || *event.RecipientID == event.ActorID {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var preference models.NotificationPreference
+		err := tx.Where("user_id = ? AND type = ?", *event.RecipientID, event.Type).Limit(1).Find(&preference).Error
+		if err != nil {
+			return err
+		}
+		if preference.UserID != 0 && !preference.Enabled {
+			return nil
+		}
+
+		// Непрочитанное уведомление группы или новое; DO UPDATE нужен, чтобы RETURNING вернул id
+		var notificationID uint
+		if err := tx.Raw(`INSERT INTO notifications (user_id, type, group_key, quote_id, comment_id, last_actor_id)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, group_key) WHERE read_at IS NULL
+			DO UPDATE SET updated_at = notifications.updated_at
+			RETURNING id`,
+			*event.RecipientID, event.Type, event.GroupKey, event.QuoteID, event.CommentID, event.ActorID,
+		).Scan(&notificationID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`INSERT INTO notification_actors (notification_id, actor_id)
+			VALUES (?, ?) ON CONFLICT DO NOTHING`, notificationID, event.ActorID)
+		if result.Error != nil {
+			return result.Error
+		}
+		// Этот пользователь уже учтен (например, снял и снова поставил лайк)
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"actor_count":   gorm.Expr("actor_count + 1"),
+			"last_actor_id": event.ActorID,
+			"updated_at":    gorm.Expr("CURRENT_TIMESTAMP"),
+		}
+		if event.CommentID != nil {
+			updates["comment_id"] = *event.CommentID
+		}
+		return tx.Model(&models.Notification{}).Where("id = ?", notificationID).Updates(updates).Error
+	})
+
+	if err != nil {
+		log.Printf("Failed to create %s notification for user %d: %v", event.Type, *event.RecipientID, err)
+	}
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"quotes-app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// canModify - менять контент может его владелец, модератор или администратор
+func canModify(c *gin.Context, ownerID *uint, userID uint) bool {
+	if ownerID != nil && *ownerID == userID {
+		return true
+	}
+	return models.IsModeratorRole(c.GetString("role"))
+}
+
+// canRestore - из корзины восстанавливают модераторы, а автор - только то, что удалил сам
+func canRestore(c *gin.Context, ownerID, deletedBy *uint) bool {
+	if models.IsModeratorRole(c.GetString("role")) {
+		return true
+	}
+	userID := c.GetUint("user_id")
+	return ownerID != nil && *ownerID == userID && deletedBy != nil && *deletedBy == userID
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// queryError - ошибка валидации одного query-параметра
+type queryError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// listOptions - допустимые параметры конкретного списка
+type listOptions struct {
+	// SortFields - whitelist полей сортировки; nil, если список не сортируется по запросу
+	SortFields   map[string]cursorColumn
+	DefaultSort  string
+	DefaultLimit int
+}
+
+// listParams - провалидированные параметры списка
+type listParams struct {
+	Page      int
+	Limit     int
+	Sort      string
+	SortField cursorColumn
+	Desc      bool
+	Cursor    string
+	UseCursor bool
+}
+
+// Offset - смещение для режима page/limit
+func (p listParams) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// OrderClause - ORDER BY по выбранному полю с id как tie-breaker
+func (p listParams) OrderClause() string {
+	direction := "ASC"
+	if p.Desc {
+		direction = "DESC"
+	}
+	return p.SortField.Expr + " " + direction + ", " + p.SortField.IDColumn + " " + direction
+}
+
+// parseListParams читает и валидирует page, limit, sort, order и cursor.
+// Ошибки собираются по всем параметрам сразу.
+func parseListParams(c *gin.Context, opts listOptions) (listParams, []queryError) {
+	var errs []queryError
+
+	defaultLimit := opts.DefaultLimit
+	if defaultLimit == 0 {
+		defaultLimit = defaultPageLimit
+	}
+
+	params := listParams{Page: 1, Limit: defaultLimit, Desc: true}
+
+	if raw, ok := c.GetQuery("page"); ok {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			errs = append(errs, queryError{Field: "page", Message: "must be a positive integer"})
+		} else {
+			params.Page = page
+		}
+	}
+
+	if raw, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			errs = append(errs, queryError{
+				Field:   "limit",
+				Message: "must be an integer between 1 and " + strconv.Itoa(maxPageLimit),
+			})
+		} else {
+			params.Limit = limit
+		}
+	}
+
+	if opts.SortFields != nil {
+		params.Sort = c.DefaultQuery("sort", opts.DefaultSort)
+		field, ok := opts.SortFields[params.Sort]
+		if !ok {
+			errs = append(errs, queryError{
+				Field:   "sort",
+				Message: "must be one of: " + strings.Join(sortedKeys(opts.SortFields), ", "),
+			})
+		}
+		params.SortField = field
+
+		switch strings.ToLower(c.DefaultQuery("order", "desc")) {
+		case "desc":
+			params.Desc = true
+		case "asc":
+			params.Desc = false
+		default:
+			errs = append(errs, queryError{Field: "order", Message: "must be asc or desc"})
+		}
+	}
+
+	if raw, ok := c.GetQuery("cursor"); ok {
+		params.Cursor = raw
+		params.UseCursor = true
+		if _, hasPage := c.GetQuery("page"); hasPage {
+			errs = append(errs, queryError{Field: "page", Message: "cannot be combined with cursor"})
+		}
+	}
+
+	return params, errs
+}
+
+// parseOptionalID читает необязательный положительный числовой идентификатор из query
+func parseOptionalID(c *gin.Context, name string, errs *[]queryError) (uint, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		*errs = append(*errs, queryError{Field: name, Message: "must be a positive integer"})
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// respondQueryErrors отвечает структурированной 400 ошибкой валидации
+func respondQueryErrors(c *gin.Context, errs []queryError) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Invalid query parameters",
+		"details": errs,
+	})
+}
+
+// paginationMeta - блок pagination в ответах списков
+func paginationMeta(page, limit int, total int64) gin.H {
+	return gin.H{
+		"page":  page,
+		"limit": limit,
+		"total": total,
+		"pages": (int(total) + limit - 1) / limit,
+	}
+}
+
+func sortedKeys(m map[string]cursorColumn) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
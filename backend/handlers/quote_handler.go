@@ -3,36 +3,60 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"quotes-app/citation"
 	"quotes-app/config"
+	"quotes-app/contentfilter"
 	"quotes-app/models"
+	"quotes-app/realtime"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type QuoteHandler struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Hub    *realtime.Hub
+	Filter *contentfilter.Pipeline
+	// DuplicateThreshold - с какой похожести новая цитата считается дублем существующей
+	DuplicateThreshold float64
 }
 
 func NewQuoteHandler() *QuoteHandler {
-	return &QuoteHandler{DB: config.DB}
+	return &QuoteHandler{
+		DB:                 config.DB,
+		Hub:                config.Hub,
+		Filter:             config.ContentFilter,
+		DuplicateThreshold: config.QuoteDuplicateSimilarity,
+	}
 }
 
 // GetQuotes - получение цитат с фильтрацией и пагинацией
 func (h *QuoteHandler) GetQuotes(c *gin.Context) {
-	var quotes []models.Quote
+	params, errs := parseListParams(c, listOptions{SortFields: quoteSortFields, DefaultSort: "created_at"})
+	categoryID, hasCategory := parseOptionalID(c, "category_id", &errs)
+	authorID, hasAuthorID := parseOptionalID(c, "author_id", &errs)
+	tagSlugs, matchAllTags := parseTagFilter(c, &errs)
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
 
-	query := h.DB.Preload("User").Preload("Category")
+	query := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).Preload("User").Preload("Category").Preload("Tags")
 
 	// Фильтрация по категории
-	if categoryID := c.Query("category_id"); categoryID != "" {
-		if id, err := strconv.Atoi(categoryID); err == nil {
-			query = query.Where("category_id = ?", id)
-		}
+	if hasCategory {
+		query = query.Where("category_id = ?", categoryID)
 	}
 
-	// Фильтрация по автору цитаты
+	// Фильтрация по автору: точно по author_id или поиском по имени
+	if hasAuthorID {
+		query = query.Where("author_id = ?", authorID)
+	}
 	if author := c.Query("author"); author != "" {
 		query = query.Where("author ILIKE ?", "%"+author+"%")
 	}
@@ -42,35 +66,167 @@ func (h *QuoteHandler) GetQuotes(c *gin.Context) {
 		query = query.Where("content ILIKE ?", "%"+content+"%")
 	}
 
-	// Сортировка
-	sort := c.DefaultQuery("sort", "created_at")
-	order := c.DefaultQuery("order", "desc")
-	query = query.Order(sort + " " + order)
+	// Фильтрация по тегам: tags_mode=all - все теги, any - хотя бы один
+	if len(tagSlugs) > 0 {
+		query = filterByTags(query, tagSlugs, matchAllTags)
+	}
+
+	// Курсорная пагинация: ?cursor= (пустое значение - первая страница)
+	if params.UseCursor {
+		page, err := fetchCursorPage(query, params.SortField, params.Desc, params.Cursor, params.Limit,
+			func(q models.Quote) (interface{}, uint) { return quoteSortValue(q, params.Sort), q.ID })
+		if err != nil {
+			if errors.Is(err, errInvalidCursor) {
+				respondQueryErrors(c, []queryError{{Field: "cursor", Message: "is invalid"}})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+			return
+		}
 
-	// Пагинация
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
+		c.JSON(http.StatusOK, gin.H{
+			"quotes":     page.Items,
+			"pagination": page.Meta(params.Limit),
+		})
+		return
+	}
 
 	var total int64
-	query.Model(&models.Quote{}).Count(&total)
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
+	}
 
-	if err := query.Offset(offset).Limit(limit).Find(&quotes).Error; err != nil {
+	var quotes []models.Quote
+	if err := query.Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&quotes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"quotes": quotes,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-			"pages": (int(total) + limit - 1) / limit,
-		},
+		"quotes":     quotes,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
 	})
 }
 
+// quoteSortFields - whitelist полей сортировки цитат
+var quoteSortFields = map[string]cursorColumn{
+	"created_at":     {Expr: "quotes.created_at", IDColumn: "quotes.id", Kind: cursorKindTime},
+	"likes_count":    {Expr: "quotes.likes_count", IDColumn: "quotes.id", Kind: cursorKindInt},
+	"dislikes_count": {Expr: "quotes.dislikes_count", IDColumn: "quotes.id", Kind: cursorKindInt},
+	"score":          {Expr: "(quotes.likes_count - quotes.dislikes_count)", IDColumn: "quotes.id", Kind: cursorKindInt},
+	"author":         {Expr: "quotes.author", IDColumn: "quotes.id", Kind: cursorKindString},
+}
+
+// quoteSortValue - значение ключа сортировки цитаты для курсора
+func quoteSortValue(q models.Quote, sort string) interface{} {
+	switch sort {
+	case "likes_count":
+		return q.LikesCount
+	case "dislikes_count":
+		return q.DislikesCount
+	case "score":
+		return q.LikesCount - q.DislikesCount
+	case "author":
+		return q.Author
+	default:
+		return q.CreatedAt
+	}
+}
+
+// maxRandomCount - сколько случайных цитат можно запросить за раз
+const maxRandomCount = 50
+
+// GetRandomQuotes - случайные цитаты, опционально с весом по рейтингу
+func (h *QuoteHandler) GetRandomQuotes(c *gin.Context) {
+	var errs []queryError
+	categoryID, hasCategory := parseOptionalID(c, "category_id", &errs)
+
+	count := 1
+	if raw, ok := c.GetQuery("count"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxRandomCount {
+			errs = append(errs, queryError{
+				Field:   "count",
+				Message: "must be an integer between 1 and " + strconv.Itoa(maxRandomCount),
+			})
+		}
+		count = n
+	}
+
+	weighted := false
+	if raw, ok := c.GetQuery("weighted"); ok {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, queryError{Field: "weighted", Message: "must be true or false"})
+		}
+		weighted = b
+	}
+
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	author := c.Query("author")
+	// Запросы идут через Table("quotes"), поэтому удаленные цитаты отсекаем явно
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = visibleQuotes(db).Where("quotes.deleted_at IS NULL")
+		if hasCategory {
+			db = db.Where("quotes.category_id = ?", categoryID)
+		}
+		if author != "" {
+			db = db.Where("quotes.author ILIKE ?", "%"+author+"%")
+		}
+		return db
+	}
+
+	// Число подходящих строк нужно только для процента выборки. Точно считаем лишь
+	// по категории (индекс); ILIKE по автору индексом не ускоряется, и точный COUNT
+	// читал бы всю таблицу, поэтому для него и без фильтров берем оценку планировщика
+	var matching int64
+	var err error
+	switch {
+	case author != "":
+		matching, err = estimateFilteredCount(h.DB, filter)
+	case hasCategory:
+		err = filter(h.DB.Table("quotes")).Count(&matching).Error
+	default:
+		matching, err = estimateQuoteCount(h.DB)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
+	}
+
+	quotes := []models.Quote{}
+	if matching > 0 {
+		sample, err := sampleQuotes(h.DB, filter, matching, count)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+			return
+		}
+
+		ids := pickRandom(sample, count, weighted)
+		if len(ids) > 0 {
+			if err := h.DB.Preload("User").Preload("Category").Preload("Tags").Where("id IN ?", ids).Find(&quotes).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+				return
+			}
+			// Возвращаем в порядке выбора, а не в порядке из БД
+			order := make(map[uint]int, len(ids))
+			for i, id := range ids {
+				order[id] = i
+			}
+			slices.SortFunc(quotes, func(a, b models.Quote) int { return order[a.ID] - order[b.ID] })
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes})
+}
+
 // GetQuoteByID - получение цитаты по ID
 func (h *QuoteHandler) GetQuoteByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -80,8 +236,8 @@ func (h *QuoteHandler) GetQuoteByID(c *gin.Context) {
 	}
 
 	var quote models.Quote
-	if err := h.DB.Preload("User").Preload("Category").
-		Preload("Comments").Preload("Comments.User").
+	if err := h.DB.Preload("User").Preload("Category").Preload("Tags").
+		Preload("Comments", visibleComments).Preload("Comments.User").
 		First(&quote, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
@@ -91,9 +247,75 @@ func (h *QuoteHandler) GetQuoteByID(c *gin.Context) {
 		return
 	}
 
+	if !canSeeQuote(c, &quote) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, quote)
 }
 
+// GetQuoteCitation - библиографическая ссылка на цитату (style=apa|mla|bibtex)
+func (h *QuoteHandler) GetQuoteCitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	style := strings.ToLower(c.DefaultQuery("style", citation.StyleAPA))
+	if !slices.Contains(citation.Styles, style) {
+		respondQueryErrors(c, []queryError{{Field: "style", Message: "must be one of: " + strings.Join(citation.Styles, ", ")}})
+		return
+	}
+
+	var quote models.Quote
+	if err := h.DB.First(&quote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		return
+	}
+
+	if !canSeeQuote(c, &quote) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+
+	work := citation.Work{
+		ID:         quote.ID,
+		Author:     quote.Author,
+		Quote:      quote.Content,
+		SourceType: derefString(quote.Source.Type),
+		Title:      derefString(quote.Source.Title),
+		Year:       quote.Source.Year,
+		Page:       derefString(quote.Source.Page),
+		URL:        derefString(quote.Source.URL),
+		AccessedAt: time.Now(),
+	}
+
+	text, err := citation.Format(style, work)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to format citation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quote_id": quote.ID,
+		"style":    style,
+		"citation": text,
+	})
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // CreateQuote - создание цитаты
 func (h *QuoteHandler) CreateQuote(c *gin.Context) {
 	var input models.QuoteCreateRequest
@@ -117,19 +339,89 @@ func (h *QuoteHandler) CreateQuote(c *gin.Context) {
 		return
 	}
 
+	content := contentfilter.Content{Kind: contentfilter.KindQuote, Text: input.Content, UserID: userIDUint}
+	filtered, ok := filterContent(c, h.DB, h.Filter, content)
+	if !ok {
+		return
+	}
+
+	// Та же цитата с другой пунктуацией или регистром уже есть - отвечаем 409.
+	// Модераторы могут сохранить ее все равно с force=true
+	force := c.Query("force") == "true"
+	if force && !models.IsModeratorRole(c.GetString("role")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can force creating a duplicate quote"})
+		return
+	}
+	if !force {
+		duplicates, err := findDuplicateQuotes(h.DB, filtered.Text, h.DuplicateThreshold)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for duplicates"})
+			return
+		}
+		if len(duplicates) > 0 {
+			ids := make([]uint, len(duplicates))
+			for i, duplicate := range duplicates {
+				ids[i] = duplicate.ID
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"error":         "Quote already exists",
+				"duplicate_ids": ids,
+				"duplicates":    duplicates,
+			})
+			return
+		}
+	}
+
 	quote := models.Quote{
-		Content:    input.Content,
+		Content:    filtered.Text,
 		Author:     input.Author,
 		CategoryID: &input.CategoryID,
 		UserID:     &userIDUint, // Теперь правильно
 	}
+	if input.Source != nil {
+		quote.Source = *input.Source
+	}
+	// Цитата, отправленная фильтром на модерацию, сохраняется скрытой
+	if filtered.Queued() {
+		now := time.Now()
+		quote.HiddenAt = &now
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Имя сопоставляется с авторами и их псевдонимами; в цитате сохраняется каноническое
+		author, err := resolveAuthor(tx, input.Author)
+		if err != nil {
+			return err
+		}
+		quote.Author = author.Name
+		quote.AuthorID = &author.ID
 
-	if err := h.DB.Create(&quote).Error; err != nil {
+		tags, err := resolveTags(tx, input.Tags)
+		if err != nil {
+			return err
+		}
+		quote.Tags = tags
+		return tx.Create(&quote).Error
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidTag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must contain letters or digits"})
+			return
+		}
+		if errors.Is(err, errInvalidAuthorName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Author name must contain letters or digits"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quote"})
 		return
 	}
 
-	h.DB.Preload("User").Preload("Category").First(&quote, quote.ID)
+	recordFilterResult(h.DB, content, filtered, quote.ID)
+
+	h.DB.Preload("User").Preload("Category").Preload("Tags").First(&quote, quote.ID)
+	if quote.HiddenAt == nil {
+		h.Hub.Publish(realtime.EventQuoteCreated, quoteTopics(quote), quote)
+	}
 	c.JSON(http.StatusCreated, quote)
 }
 
@@ -155,8 +447,8 @@ func (h *QuoteHandler) UpdateQuote(c *gin.Context) {
 		return
 	}
 
-	// Проверяем владельца
-	if quote.UserID == nil || *quote.UserID != userIDUint {
+	// Проверяем владельца (модераторы могут менять любой контент)
+	if !canModify(c, quote.UserID, userIDUint) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own quotes"})
 		return
 	}
@@ -169,11 +461,17 @@ func (h *QuoteHandler) UpdateQuote(c *gin.Context) {
 
 	// Обновляем только переданные поля
 	updates := make(map[string]interface{})
+	content := contentfilter.Content{Kind: contentfilter.KindQuote, Text: input.Content, UserID: userIDUint, ExcludeID: quote.ID}
+	var filtered contentfilter.Result
 	if input.Content != "" {
-		updates["content"] = input.Content
-	}
-	if input.Author != "" {
-		updates["author"] = input.Author
+		var ok bool
+		if filtered, ok = filterContent(c, h.DB, h.Filter, content); !ok {
+			return
+		}
+		updates["content"] = filtered.Text
+		if filtered.Queued() {
+			updates["hidden_at"] = time.Now()
+		}
 	}
 	if input.CategoryID != 0 {
 		// Проверяем существование категории
@@ -184,13 +482,55 @@ func (h *QuoteHandler) UpdateQuote(c *gin.Context) {
 		}
 		updates["category_id"] = input.CategoryID
 	}
+	if input.Source != nil {
+		updates["source_type"] = input.Source.Type
+		updates["source_title"] = input.Source.Title
+		updates["source_year"] = input.Source.Year
+		updates["source_page"] = input.Source.Page
+		updates["source_url"] = input.Source.URL
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Author != "" {
+			author, err := resolveAuthor(tx, input.Author)
+			if err != nil {
+				return err
+			}
+			updates["author"] = author.Name
+			updates["author_id"] = author.ID
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&quote).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
 
-	if err := h.DB.Model(&quote).Updates(updates).Error; err != nil {
+		if input.Tags == nil {
+			return nil
+		}
+		tags, err := resolveTags(tx, *input.Tags)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&quote).Association("Tags").Replace(tags)
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidTag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must contain letters or digits"})
+			return
+		}
+		if errors.Is(err, errInvalidAuthorName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Author name must contain letters or digits"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quote"})
 		return
 	}
 
-	h.DB.Preload("User").Preload("Category").First(&quote, quote.ID)
+	recordFilterResult(h.DB, content, filtered, quote.ID)
+
+	h.DB.Preload("User").Preload("Category").Preload("Tags").First(&quote, quote.ID)
 	c.JSON(http.StatusOK, quote)
 }
 
@@ -216,13 +556,17 @@ func (h *QuoteHandler) DeleteQuote(c *gin.Context) {
 		return
 	}
 
-	// Проверяем владельца
-	if quote.UserID == nil || *quote.UserID != userIDUint {
+	// Проверяем владельца (модераторы могут менять любой контент)
+	if !canModify(c, quote.UserID, userIDUint) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own quotes"})
 		return
 	}
 
-	if err := h.DB.Delete(&quote).Error; err != nil {
+	// Цитата уходит в корзину: комментарии и реакции сохраняются до окончательной очистки
+	if err := h.DB.Model(&quote).UpdateColumns(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": userIDUint,
+	}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quote"})
 		return
 	}
@@ -230,6 +574,42 @@ func (h *QuoteHandler) DeleteQuote(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Quote deleted successfully"})
 }
 
+// RestoreQuote - восстановление цитаты из корзины
+func (h *QuoteHandler) RestoreQuote(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	var quote models.Quote
+	if err := h.DB.Unscoped().First(&quote, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+
+	if !canRestore(c, quote.UserID, quote.DeletedBy) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only restore quotes you deleted yourself"})
+		return
+	}
+
+	if !quote.DeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Quote is not deleted"})
+		return
+	}
+
+	if err := h.DB.Unscoped().Model(&quote).UpdateColumns(map[string]interface{}{
+		"deleted_at": nil,
+		"deleted_by": nil,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore quote"})
+		return
+	}
+
+	h.DB.Preload("User").Preload("Category").Preload("Tags").First(&quote, quote.ID)
+	c.JSON(http.StatusOK, quote)
+}
+
 // LikeQuote - лайк цитаты
 func (h *QuoteHandler) LikeQuote(c *gin.Context) {
 	h.handleQuoteReaction(c, "like")
@@ -259,12 +639,15 @@ func (h *QuoteHandler) handleQuoteReaction(c *gin.Context, reactionType string)
 	result := h.DB.Where("quote_id = ? AND user_id = ?", quoteID, userIDUint).First(&existingLike)
 
 	// Начинаем транзакцию
+	var quote models.Quote
+	liked := false
 	err = h.DB.Transaction(func(tx *gorm.DB) error {
-		var quote models.Quote
-		if err := tx.First(&quote, quoteID).Error; err != nil {
+		// На скрытые по жалобам цитаты реагировать нельзя
+		if err := tx.Scopes(visibleQuotes).First(&quote, quoteID).Error; err != nil {
 			return err
 		}
 
+		var likes, dislikes int
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			// Создаем новую реакцию
 			like := models.QuoteLike{
@@ -278,9 +661,10 @@ func (h *QuoteHandler) handleQuoteReaction(c *gin.Context, reactionType string)
 
 			// Обновляем счетчики
 			if reactionType == "like" {
-				quote.LikesCount++
+				likes++
+				liked = true
 			} else {
-				quote.DislikesCount++
+				dislikes++
 			}
 		} else {
 			if existingLike.Type == reactionType {
@@ -290,9 +674,9 @@ func (h *QuoteHandler) handleQuoteReaction(c *gin.Context, reactionType string)
 				}
 				// Уменьшаем счетчик
 				if reactionType == "like" {
-					quote.LikesCount--
+					likes--
 				} else {
-					quote.DislikesCount--
+					dislikes--
 				}
 			} else {
 				// Меняем реакцию
@@ -302,16 +686,31 @@ func (h *QuoteHandler) handleQuoteReaction(c *gin.Context, reactionType string)
 				}
 				// Обновляем счетчики
 				if reactionType == "like" {
-					quote.LikesCount++
-					quote.DislikesCount--
+					likes++
+					dislikes--
+					liked = true
 				} else {
-					quote.LikesCount--
-					quote.DislikesCount++
+					likes--
+					dislikes++
 				}
 			}
 		}
 
-		return tx.Save(&quote).Error
+		// Меняем только счетчики: Save перезаписал бы hidden_at и deleted_at,
+		// выставленные параллельно жалобой или удалением
+		update := tx.Model(&quote).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "likes_count"}, {Name: "dislikes_count"}}}).
+			UpdateColumns(map[string]interface{}{
+				"likes_count":    gorm.Expr("likes_count + ?", likes),
+				"dislikes_count": gorm.Expr("dislikes_count + ?", dislikes),
+			})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -323,5 +722,21 @@ func (h *QuoteHandler) handleQuoteReaction(c *gin.Context, reactionType string)
 		return
 	}
 
+	if liked {
+		notify(h.DB, models.NotificationEvent{
+			RecipientID: quote.UserID,
+			ActorID:     userIDUint,
+			Type:        models.NotificationQuoteLike,
+			QuoteID:     &quote.ID,
+			GroupKey:    notificationGroup(models.NotificationQuoteLike, quote.ID),
+		})
+	}
+
+	h.Hub.Publish(realtime.EventQuoteReaction, quoteTopics(quote), gin.H{
+		"quote_id":       quote.ID,
+		"likes_count":    quote.LikesCount,
+		"dislikes_count": quote.DislikesCount,
+	})
+
 	c.JSON(http.StatusOK, gin.H{"message": "Reaction updated successfully"})
 }
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errAlreadyReported  = errors.New("already reported")
+	errReportOwnContent = errors.New("cannot report own content")
+	errReportReviewed   = errors.New("report already reviewed")
+)
+
+// reportSortFields - жалобы сортируются только по времени (order=asc - сначала старые)
+var reportSortFields = map[string]cursorColumn{
+	"created_at": {Expr: "reports.created_at", IDColumn: "reports.id", Kind: cursorKindTime},
+}
+
+// filterLogSortFields - журнал фильтра сортируется только по времени
+var filterLogSortFields = map[string]cursorColumn{
+	"created_at": {Expr: "content_filter_logs.created_at", IDColumn: "content_filter_logs.id", Kind: cursorKindTime},
+}
+
+type ReportHandler struct {
+	DB            *gorm.DB
+	HideThreshold int
+}
+
+func NewReportHandler() *ReportHandler {
+	return &ReportHandler{DB: config.DB, HideThreshold: config.ReportHideThreshold}
+}
+
+// visibleQuotes - цитаты, не скрытые по жалобам
+func visibleQuotes(db *gorm.DB) *gorm.DB {
+	return db.Where("quotes.hidden_at IS NULL")
+}
+
+// canSeeQuote - скрытую по жалобам цитату (и ее комментарии) видят только автор и модераторы
+func canSeeQuote(c *gin.Context, quote *models.Quote) bool {
+	return quote.HiddenAt == nil || canModify(c, quote.UserID, c.GetUint("user_id"))
+}
+
+// visibleComments - комментарии, не скрытые по жалобам
+func visibleComments(db *gorm.DB) *gorm.DB {
+	return db.Where("comments.hidden_at IS NULL")
+}
+
+// reportTargetTable - таблица объекта жалобы; target берется только из констант models
+func reportTargetTable(target string) string {
+	if target == models.ReportTargetComment {
+		return "comments"
+	}
+	return "quotes"
+}
+
+// reportTargetScope - жалобы на тот же объект, что и report
+func reportTargetScope(db *gorm.DB, report models.Report) *gorm.DB {
+	if report.TargetType == models.ReportTargetComment {
+		return db.Where("comment_id = ?", *report.CommentID)
+	}
+	return db.Where("quote_id = ?", *report.QuoteID)
+}
+
+// ReportQuote - жалоба на цитату
+func (h *ReportHandler) ReportQuote(c *gin.Context) {
+	h.createReport(c, models.ReportTargetQuote)
+}
+
+// ReportComment - жалоба на комментарий
+func (h *ReportHandler) ReportComment(c *gin.Context) {
+	h.createReport(c, models.ReportTargetComment)
+}
+
+// createReport сохраняет жалобу и скрывает объект, если набралось HideThreshold нерассмотренных жалоб
+func (h *ReportHandler) createReport(c *gin.Context, target string) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + target + " ID"})
+		return
+	}
+
+	var input models.ReportCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	id := uint(targetID)
+	report := models.Report{
+		ReporterID: &userID,
+		TargetType: target,
+		Reason:     input.Reason,
+	}
+	if input.Details != "" {
+		report.Details = &input.Details
+	}
+
+	hidden := false
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var ownerID *uint
+		if target == models.ReportTargetComment {
+			var comment models.Comment
+			if err := tx.Select("id", "user_id").First(&comment, id).Error; err != nil {
+				return err
+			}
+			ownerID = comment.UserID
+			report.CommentID = &id
+		} else {
+			var quote models.Quote
+			if err := tx.Select("id", "user_id").First(&quote, id).Error; err != nil {
+				return err
+			}
+			ownerID = quote.UserID
+			report.QuoteID = &id
+		}
+		if ownerID != nil && *ownerID == userID {
+			return errReportOwnContent
+		}
+
+		// Повторная жалоба до рассмотрения упирается в частичный уникальный индекс
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyReported
+		}
+
+		var pending int64
+		if err := reportTargetScope(tx.Model(&models.Report{}), report).
+			Where("status = ?", models.ReportPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending < int64(h.HideThreshold) {
+			return nil
+		}
+
+		// UpdateColumn не трогает updated_at: скрытие - не редактирование
+		update := tx.Table(reportTargetTable(target)).
+			Where("id = ? AND hidden_at IS NULL", id).
+			UpdateColumn("hidden_at", time.Now())
+		hidden = update.RowsAffected > 0
+		return update.Error
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if target == models.ReportTargetComment {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			} else {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+			}
+		case errors.Is(err, errReportOwnContent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report your own " + target})
+		case errors.Is(err, errAlreadyReported):
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this " + target})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit report"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Report submitted",
+		"report":  report,
+		"hidden":  hidden,
+	})
+}
+
+// GetReports - очередь жалоб для модераторов
+func (h *ReportHandler) GetReports(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{SortFields: reportSortFields, DefaultSort: "created_at"})
+
+	status := c.DefaultQuery("status", models.ReportPending)
+	if status != models.ReportPending && status != models.ReportResolved && status != models.ReportDismissed {
+		errs = append(errs, queryError{Field: "status", Message: "must be pending, resolved or dismissed"})
+	}
+	target := c.Query("target_type")
+	if target != "" && target != models.ReportTargetQuote && target != models.ReportTargetComment {
+		errs = append(errs, queryError{Field: "target_type", Message: "must be quote or comment"})
+	}
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	query := h.DB.Model(&models.Report{}).Where("status = ?", status)
+	if target != "" {
+		query = query.Where("target_type = ?", target)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+
+	var reports []models.Report
+	if err := query.Preload("Reporter").
+		Preload("Quote").Preload("Quote.User").
+		Preload("Comment").Preload("Comment.User").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports":    reports,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// ResolveReport - жалоба обоснована: объект остается скрытым, все жалобы на него закрываются
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	h.reviewReport(c, models.ReportResolved)
+}
+
+// DismissReport - жалоба необоснована: объект снова виден, все жалобы на него отклоняются
+func (h *ReportHandler) DismissReport(c *gin.Context) {
+	h.reviewReport(c, models.ReportDismissed)
+}
+
+func (h *ReportHandler) reviewReport(c *gin.Context, status string) {
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	var input models.ReportReviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	moderatorID := c.GetUint("user_id")
+	var reviewed int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var report models.Report
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&report, reportID).Error; err != nil {
+			return err
+		}
+		if report.Status != models.ReportPending {
+			return errReportReviewed
+		}
+
+		updates := map[string]interface{}{
+			"status":      status,
+			"resolved_by": moderatorID,
+			"resolved_at": time.Now(),
+		}
+		if input.Note != "" {
+			updates["resolution_note"] = input.Note
+		}
+
+		result := reportTargetScope(tx.Model(&models.Report{}), report).
+			Where("status = ?", models.ReportPending).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		reviewed = result.RowsAffected
+
+		hiddenAt := interface{}(nil)
+		if status == models.ReportResolved {
+			hiddenAt = gorm.Expr("COALESCE(hidden_at, NOW())")
+		}
+		targetID := report.QuoteID
+		if report.TargetType == models.ReportTargetComment {
+			targetID = report.CommentID
+		}
+		return tx.Table(reportTargetTable(report.TargetType)).
+			Where("id = ?", *targetID).
+			UpdateColumn("hidden_at", hiddenAt).Error
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		case errors.Is(err, errReportReviewed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Report has already been reviewed"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review report"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Report " + status,
+		"reports_reviewed": reviewed,
+	})
+}
+
+// GetFilterLog - журнал решений фильтра контента
+func (h *ReportHandler) GetFilterLog(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{SortFields: filterLogSortFields, DefaultSort: "created_at"})
+	userID, hasUser := parseOptionalID(c, "user_id", &errs)
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	query := h.DB.Model(&models.ContentFilterLog{})
+	if hasUser {
+		query = query.Where("user_id = ?", userID)
+	}
+	if filter := c.Query("filter"); filter != "" {
+		query = query.Where("filter = ?", filter)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch filter log"})
+		return
+	}
+
+	var entries []models.ContentFilterLog
+	if err := query.Preload("User").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch filter log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":    entries,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
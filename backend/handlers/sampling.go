@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// scoredQuote - id цитаты и ее рейтинг (лайки минус дизлайки)
+type scoredQuote struct {
+	ID    uint
+	Score int
+}
+
+// Weight - вес цитаты при случайном выборе: max(score, 0) + 1,
+// так что популярные цитаты выпадают чаще, но у остальных тоже есть шанс
+func (q scoredQuote) Weight() int {
+	return max(q.Score, 0) + 1
+}
+
+// Параметры выборки для случайных цитат
+const (
+	// sampleOversample - во сколько раз больше строк берем в выборку, чем нужно вернуть
+	sampleOversample = 10
+	// minSampleSize - меньше этого числа строк выбираем все кандидаты без TABLESAMPLE
+	minSampleSize = 200
+)
+
+// sampleQuotes возвращает случайную выборку кандидатов примерно из want*sampleOversample строк.
+// Вместо ORDER BY random() используется TABLESAMPLE BERNOULLI: Postgres не сортирует
+// таблицу, а оставляет каждую строку с заданной вероятностью. Страницы таблицы при этом
+// все равно читаются целиком - экономия только на сортировке и передаче строк.
+// filter применяет к запросу условия WHERE; matching - (оценка) числа подходящих строк.
+func sampleQuotes(db *gorm.DB, filter func(*gorm.DB) *gorm.DB, matching int64, want int) ([]scoredQuote, error) {
+	target := max(want*sampleOversample, minSampleSize)
+
+	query := db.Table("quotes")
+	if matching > int64(target) {
+		// Строка попадает в выборку независимо от фильтров, поэтому
+		// в среднем после WHERE остается matching * percent / 100 = target строк
+		percent := float64(target) * 100 / float64(matching)
+		query = db.Table("quotes TABLESAMPLE BERNOULLI (CAST(? AS REAL))", percent)
+	}
+
+	sample, err := scanSample(filter(query), target*2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выборке не повезло или оценка числа строк занижена (выборка уперлась в LIMIT
+	// и оказалась бы смещена к началу таблицы) - добираем честной сортировкой,
+	// она выполняется только по подходящим строкам
+	if (len(sample) < want && matching > int64(target)) || len(sample) >= target*2 {
+		return scanSample(filter(db.Table("quotes")).Order("random()"), target)
+	}
+
+	return sample, nil
+}
+
+func scanSample(query *gorm.DB, limit int) ([]scoredQuote, error) {
+	var sample []scoredQuote
+	err := query.
+		Select("quotes.id, quotes.likes_count - quotes.dislikes_count AS score").
+		Limit(limit).
+		Scan(&sample).Error
+	return sample, err
+}
+
+// estimateQuoteCount - быстрая оценка числа цитат из статистики планировщика.
+// Если таблица еще не анализировалась, считаем точно.
+func estimateQuoteCount(db *gorm.DB) (int64, error) {
+	var estimate float64
+	if err := db.Raw("SELECT reltuples FROM pg_class WHERE oid = 'quotes'::regclass").
+		Scan(&estimate).Error; err != nil {
+		return 0, err
+	}
+	if estimate > 0 {
+		return int64(estimate), nil
+	}
+
+	var count int64
+	err := db.Table("quotes").Count(&count).Error
+	return count, err
+}
+
+// estimateFilteredCount - оценка числа подходящих под filter цитат из плана запроса (EXPLAIN).
+// Таблица не читается; неточность оценки компенсирует проверка в sampleQuotes.
+func estimateFilteredCount(db *gorm.DB, filter func(*gorm.DB) *gorm.DB) (int64, error) {
+	var raw string
+	if err := db.Raw("EXPLAIN (FORMAT JSON) ?", filter(db.Table("quotes")).Select("quotes.id")).
+		Scan(&raw).Error; err != nil {
+		return 0, err
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(raw), &plans); err != nil || len(plans) == 0 {
+		return 0, err
+	}
+	return int64(plans[0].Plan.Rows), nil
+}
+
+// pickRandom выбирает count разных цитат из выборки.
+// При weighted используется алгоритм Эфраимидиса-Спиракиса: ключ u^(1/w),
+// берутся count цитат с наибольшими ключами.
+func pickRandom(sample []scoredQuote, count int, weighted bool) []uint {
+	type keyed struct {
+		id  uint
+		key float64
+	}
+
+	keys := make([]keyed, len(sample))
+	for i, q := range sample {
+		key := rand.Float64()
+		if weighted {
+			key = math.Pow(key, 1/float64(q.Weight()))
+		}
+		keys[i] = keyed{id: q.ID, key: key}
+	}
+
+	slices.SortFunc(keys, func(a, b keyed) int {
+		switch {
+		case a.key > b.key:
+			return -1
+		case a.key < b.key:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	ids := make([]uint, 0, min(count, len(keys)))
+	for _, k := range keys[:min(count, len(keys))] {
+		ids = append(ids, k.id)
+	}
+	return ids
+}
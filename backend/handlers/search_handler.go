@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SearchHandler struct {
+	DB *gorm.DB
+}
+
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{DB: config.DB}
+}
+
+// searchConfig - конфигурация полнотекстового поиска и соответствующая колонка tsvector
+type searchConfig struct {
+	Name   string
+	Column string
+}
+
+var searchConfigs = map[string]searchConfig{
+	"ru": {Name: "russian", Column: "search_vector_ru"},
+	"en": {Name: "english", Column: "search_vector_en"},
+}
+
+// HTML экранируется до ts_headline, чтобы в ответе были только наши теги <mark>
+const headlineSQL = `ts_headline('%s',
+	replace(replace(replace(content, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+	websearch_to_tsquery('%s', ?),
+	'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')`
+
+type searchHit struct {
+	ID       uint
+	Rank     float64
+	Headline string
+}
+
+// Search - полнотекстовый поиск цитат с ранжированием и подсветкой
+func (h *SearchHandler) Search(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{})
+	categoryID, hasCategory := parseOptionalID(c, "category_id", &errs)
+
+	q := c.Query("q")
+	if q == "" {
+		errs = append(errs, queryError{Field: "q", Message: "is required"})
+	}
+
+	lang := c.DefaultQuery("lang", "auto")
+	if lang == "auto" {
+		lang = detectLanguage(q)
+	}
+	cfg, ok := searchConfigs[lang]
+	if !ok {
+		errs = append(errs, queryError{Field: "lang", Message: "must be ru, en or auto"})
+	}
+
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	// cfg берется только из searchConfigs, поэтому подстановка в SQL безопасна
+	tsQuery := "websearch_to_tsquery('" + cfg.Name + "', ?)"
+	query := h.DB.Table("quotes").Scopes(visibleQuotes).Where("quotes.deleted_at IS NULL").Where(cfg.Column+" @@ "+tsQuery, q)
+
+	if hasCategory {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search quotes"})
+		return
+	}
+
+	var hits []searchHit
+	if err := query.
+		Select("id, ts_rank("+cfg.Column+", "+tsQuery+") AS rank, "+
+			fmt.Sprintf(headlineSQL, cfg.Name, cfg.Name)+" AS headline", q, q).
+		Order("rank DESC, id DESC").
+		Offset(params.Offset()).Limit(params.Limit).
+		Scan(&hits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search quotes"})
+		return
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	var quotes []models.Quote
+	if len(ids) > 0 {
+		if err := h.DB.Preload("User").Preload("Category").Preload("Tags").Find(&quotes, ids).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+			return
+		}
+	}
+
+	quotesByID := make(map[uint]models.Quote, len(quotes))
+	for _, quote := range quotes {
+		quotesByID[quote.ID] = quote
+	}
+
+	// Сохраняем порядок по релевантности
+	results := make([]gin.H, 0, len(hits))
+	for _, hit := range hits {
+		quote, ok := quotesByID[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, gin.H{
+			"quote":    quote,
+			"rank":     hit.Rank,
+			"headline": hit.Headline,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":    results,
+		"lang":       lang,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// detectLanguage выбирает русскую конфигурацию, если в запросе есть кириллица
+func detectLanguage(q string) string {
+	for _, r := range q {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return "ru"
+		}
+	}
+	return "en"
+}
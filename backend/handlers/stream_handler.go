@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"quotes-app/realtime"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// streamHeartbeat - как часто слать ping, чтобы прокси не закрывали простаивающее соединение
+const streamHeartbeat = 25 * time.Second
+
+// maxStreamTopics - сколько топиков может держать одно WebSocket-соединение
+const maxStreamTopics = 50
+
+type StreamHandler struct {
+	Hub *realtime.Hub
+}
+
+func NewStreamHandler() *StreamHandler {
+	return &StreamHandler{Hub: config.Hub}
+}
+
+// streamMessage - команда клиента WebSocket: {"action": "subscribe", "topic": "quote:42"}
+type streamMessage struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+// streamReply - ответ на команду клиента WebSocket
+type streamReply struct {
+	Type   string   `json:"type"`
+	Topics []string `json:"topics,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// quoteTopics - топики, в которые попадают события цитаты
+func quoteTopics(quote models.Quote) []string {
+	topics := []string{realtime.TopicQuotes, realtime.QuoteTopic(quote.ID)}
+	if quote.CategoryID != nil {
+		topics = append(topics, realtime.CategoryTopic(*quote.CategoryID))
+	}
+	return topics
+}
+
+// streamTopics - топики из query: quote_id, category_id, иначе все цитаты
+func streamTopics(c *gin.Context) ([]string, []queryError) {
+	var errs []queryError
+	quoteID, hasQuote := parseOptionalID(c, "quote_id", &errs)
+	categoryID, hasCategory := parseOptionalID(c, "category_id", &errs)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var topics []string
+	if hasQuote {
+		topics = append(topics, realtime.QuoteTopic(quoteID))
+	}
+	if hasCategory {
+		topics = append(topics, realtime.CategoryTopic(categoryID))
+	}
+	if len(topics) == 0 {
+		topics = append(topics, realtime.TopicQuotes)
+	}
+	return topics, nil
+}
+
+// Stream - поток событий через Server-Sent Events
+func (h *StreamHandler) Stream(c *gin.Context) {
+	topics, errs := streamTopics(c)
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	sub := h.Hub.Subscribe(topics)
+	defer h.Hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Отключаем буферизацию в nginx, иначе события приходят пачками
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.SSEvent("ready", gin.H{"topics": topics})
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Клиент не успевал читать события - закрываем, он переподключится
+				return
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"time": time.Now().UTC()})
+			c.Writer.Flush()
+		}
+	}
+}
+
+// WebSocket - поток событий через WebSocket с подпиской на топики командами клиента
+func (h *StreamHandler) WebSocket(c *gin.Context) {
+	topics, errs := streamTopics(c)
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	server := websocket.Server{
+		// Проверку Origin не делаем: API и так открыт для любого источника (см. CORS)
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.serveWebSocket(conn, topics)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *StreamHandler) serveWebSocket(conn *websocket.Conn, topics []string) {
+	defer conn.Close()
+
+	sub := h.Hub.Subscribe(topics)
+	defer h.Hub.Unsubscribe(sub)
+
+	// Все записи в соединение идут из одной горутины; stop освобождает читателя после выхода
+	replies := make(chan streamReply, 8)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.readWebSocket(conn, sub, replies, done, stop)
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	var err error
+	if err = websocket.JSON.Send(conn, streamReply{Type: "ready", Topics: topics}); err != nil {
+		return
+	}
+
+	for err == nil {
+		select {
+		case <-done:
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			err = websocket.JSON.Send(conn, event)
+		case reply := <-replies:
+			err = websocket.JSON.Send(conn, reply)
+		case <-heartbeat.C:
+			err = websocket.JSON.Send(conn, streamReply{Type: "ping"})
+		}
+	}
+}
+
+// readWebSocket обрабатывает команды subscribe/unsubscribe до закрытия соединения
+func (h *StreamHandler) readWebSocket(conn *websocket.Conn, sub *realtime.Subscription, replies chan<- streamReply, done, stop chan struct{}) {
+	defer close(done)
+
+	reply := func(r streamReply) bool {
+		select {
+		case replies <- r:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+
+	for {
+		var message streamMessage
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			return
+		}
+
+		topic, err := realtime.ParseTopic(message.Topic)
+		if err != nil {
+			if !reply(streamReply{Type: "error", Error: "Unknown topic"}) {
+				return
+			}
+			continue
+		}
+
+		topics := sub.Topics()
+		switch message.Action {
+		case "subscribe":
+			if slices.Contains(topics, topic) {
+				break
+			}
+			if len(topics) >= maxStreamTopics {
+				if !reply(streamReply{Type: "error", Error: "Too many subscriptions"}) {
+					return
+				}
+				continue
+			}
+			topics = append(topics, topic)
+		case "unsubscribe":
+			topics = slices.DeleteFunc(topics, func(t string) bool { return t == topic })
+		default:
+			if !reply(streamReply{Type: "error", Error: "Action must be subscribe or unsubscribe"}) {
+				return
+			}
+			continue
+		}
+
+		sub.SetTopics(topics)
+		slices.Sort(topics)
+		if !reply(streamReply{Type: "subscribed", Topics: topics}) {
+			return
+		}
+	}
+}
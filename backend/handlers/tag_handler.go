@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxTagLength = 50
+	// maxTagFilter - сколько тегов можно передать в фильтр списка цитат
+	maxTagFilter = 10
+)
+
+var errInvalidTag = errors.New("invalid tag")
+
+type TagHandler struct {
+	DB *gorm.DB
+}
+
+func NewTagHandler() *TagHandler {
+	return &TagHandler{DB: config.DB}
+}
+
+// tagSortFields - whitelist полей сортировки тегов
+var tagSortFields = map[string]cursorColumn{
+	"quotes_count": {Expr: "quotes_count", IDColumn: "tags.id", Kind: cursorKindInt},
+	"name":         {Expr: "tags.slug", IDColumn: "tags.id", Kind: cursorKindString},
+}
+
+// GetTags - используемые теги с количеством цитат; prefix - автодополнение
+func (h *TagHandler) GetTags(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{SortFields: tagSortFields, DefaultSort: "quotes_count", DefaultLimit: 20})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	// Показываем только теги, у которых есть цитаты
+	query := h.DB.Model(&models.Tag{}).
+		Where("EXISTS (SELECT 1 FROM quote_tags JOIN quotes ON quotes.id = quote_tags.quote_id WHERE quote_tags.tag_id = tags.id AND quotes.deleted_at IS NULL AND quotes.hidden_at IS NULL)")
+
+	if prefix := normalizeTagSlug(c.Query("prefix")); prefix != "" {
+		query = query.Where("tags.slug LIKE ?", prefix+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	var tags []models.Tag
+	if err := query.
+		Select("tags.*, (SELECT COUNT(*) FROM quote_tags JOIN quotes ON quotes.id = quote_tags.quote_id WHERE quote_tags.tag_id = tags.id AND quotes.deleted_at IS NULL AND quotes.hidden_at IS NULL) AS quotes_count").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags":       tags,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// normalizeTagSlug приводит имя тега к slug: нижний регистр, буквы и цифры,
+// остальные символы превращаются в одиночные дефисы
+func normalizeTagSlug(name string) string {
+	var b strings.Builder
+	pendingDash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		} else {
+			pendingDash = true
+		}
+	}
+
+	slug := []rune(b.String())
+	if len(slug) > maxTagLength {
+		slug = slug[:maxTagLength]
+	}
+	return strings.TrimRight(string(slug), "-")
+}
+
+// resolveTags находит или создает теги по именам; дубликаты по slug схлопываются
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	slugs := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		slug := normalizeTagSlug(name)
+		if slug == "" {
+			return nil, errInvalidTag
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+		tags = append(tags, models.Tag{Name: strings.TrimSpace(name), Slug: slug})
+	}
+
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	// Существующие теги сохраняют свое имя
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slug"}},
+		DoNothing: true,
+	}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	var resolved []models.Tag
+	if err := tx.Where("slug IN ?", slugs).Find(&resolved).Error; err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// parseTagFilter читает tags=a,b и tags_mode=all|any для фильтрации цитат
+func parseTagFilter(c *gin.Context, errs *[]queryError) (slugs []string, matchAll bool) {
+	matchAll = true
+	switch strings.ToLower(c.DefaultQuery("tags_mode", "all")) {
+	case "all":
+	case "any":
+		matchAll = false
+	default:
+		*errs = append(*errs, queryError{Field: "tags_mode", Message: "must be all or any"})
+	}
+
+	raw := c.Query("tags")
+	if raw == "" {
+		return nil, matchAll
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		slug := normalizeTagSlug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+
+	if len(slugs) == 0 {
+		*errs = append(*errs, queryError{Field: "tags", Message: "must contain at least one valid tag"})
+	} else if len(slugs) > maxTagFilter {
+		*errs = append(*errs, queryError{Field: "tags", Message: "must contain at most 10 tags"})
+	}
+
+	return slugs, matchAll
+}
+
+// filterByTags оставляет цитаты со всеми (matchAll) или хотя бы одним из тегов
+func filterByTags(query *gorm.DB, slugs []string, matchAll bool) *gorm.DB {
+	if matchAll {
+		return query.Where(`quotes.id IN (SELECT quote_tags.quote_id FROM quote_tags
+			JOIN tags ON tags.id = quote_tags.tag_id
+			WHERE tags.slug IN ?
+			GROUP BY quote_tags.quote_id
+			HAVING COUNT(*) = ?)`, slugs, len(slugs))
+	}
+
+	return query.Where(`quotes.id IN (SELECT quote_tags.quote_id FROM quote_tags
+		JOIN tags ON tags.id = quote_tags.tag_id
+		WHERE tags.slug IN ?)`, slugs)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxTrashItems - сколько последних удаленных цитат и комментариев показывается в корзине
+const maxTrashItems = 100
+
+type TrashHandler struct {
+	DB        *gorm.DB
+	Retention time.Duration
+}
+
+func NewTrashHandler() *TrashHandler {
+	return &TrashHandler{DB: config.DB, Retention: config.TrashRetention}
+}
+
+// trashedQuote - цитата в корзине и время ее окончательного удаления
+type trashedQuote struct {
+	models.Quote
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// trashedComment - комментарий в корзине и время его окончательного удаления
+type trashedComment struct {
+	models.Comment
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// GetTrash - корзина текущего пользователя: то, что он удалил сам и может восстановить
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var quotes []models.Quote
+	if err := h.DB.Unscoped().Preload("Category").Preload("Tags").
+		Where("user_id = ? AND deleted_by = ? AND deleted_at IS NOT NULL", userID, userID).
+		Order("deleted_at DESC").
+		Limit(maxTrashItems).
+		Find(&quotes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	var comments []models.Comment
+	if err := h.DB.Unscoped().
+		Where("user_id = ? AND deleted_by = ? AND deleted_at IS NOT NULL", userID, userID).
+		Order("deleted_at DESC").
+		Limit(maxTrashItems).
+		Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	trashedQuotes := make([]trashedQuote, len(quotes))
+	for i, quote := range quotes {
+		deletedAt := quote.DeletedAt.Time
+		trashedQuotes[i] = trashedQuote{Quote: quote, DeletedAt: deletedAt, PurgeAt: deletedAt.Add(h.Retention)}
+	}
+
+	trashedComments := make([]trashedComment, len(comments))
+	for i, comment := range comments {
+		deletedAt := comment.DeletedAt.Time
+		trashedComments[i] = trashedComment{Comment: comment, DeletedAt: deletedAt, PurgeAt: deletedAt.Add(h.Retention)}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotes":         trashedQuotes,
+		"comments":       trashedComments,
+		"retention_days": int(h.Retention.Hours() / 24),
+	})
+}
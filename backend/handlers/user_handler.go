@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"quotes-app/config"
+	"quotes-app/mailer"
+	"quotes-app/models"
+	"quotes-app/throttle"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UserHandler struct {
+	DB     *gorm.DB
+	Mailer mailer.Mailer
+	Guard  *throttle.LoginGuard
+}
+
+func NewUserHandler() *UserHandler {
+	return &UserHandler{DB: config.DB, Mailer: config.Mailer, Guard: config.LoginGuard}
+}
+
+// ListUsers - список пользователей для администратора
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	params, errs := parseListParams(c, listOptions{DefaultLimit: 20})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var users []models.User
+
+	query := h.DB.Model(&models.User{})
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	if search := c.Query("search"); search != "" {
+		query = query.Where("username ILIKE ? OR email ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Order("id ASC").Offset(params.Offset()).Limit(params.Limit).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":      users,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// UpdateUserRole - смена роли пользователя администратором
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input models.UserRoleUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Администратор не может случайно лишить прав сам себя
+	if uint(id) == c.GetUint("user_id") && input.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+		return
+	}
+
+	var user models.User
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Update("role", input.Role).Error; err != nil {
+			return err
+		}
+
+		// Роль хранится в access токене, поэтому отзываем сессии,
+		// чтобы новые права вступили в силу сразу
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", time.Now()).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser - удаление пользователя администратором
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if uint(id) == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot delete yourself"})
+		return
+	}
+
+	result := h.DB.Delete(&models.User{}, id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// UnlockUser - снятие блокировки входа с аккаунта (и, опционально, с IP) администратором
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		IP string `json:"ip" binding:"omitempty,ip"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := h.Guard.UnlockAccount(user.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	if input.IP != "" {
+		if err := h.Guard.UnlockIP(input.IP); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock IP"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// GetMe - профиль текущего пользователя (с email)
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	stats, err := h.userStats(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":  user,
+		"stats": stats,
+	})
+}
+
+// UpdateMe - изменение username или email текущего пользователя
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var input models.UserProfileUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Обновляем только переданные поля
+	updates := make(map[string]interface{})
+	if input.Username != "" && input.Username != user.Username {
+		if h.fieldTaken("username", input.Username, user.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username is already taken"})
+			return
+		}
+		updates["username"] = input.Username
+	}
+	if input.Email != "" && input.Email != user.Email {
+		if h.fieldTaken("email", input.Email, user.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already taken"})
+			return
+		}
+		// Новый адрес нужно подтвердить заново
+		updates["email"] = input.Email
+		updates["email_verified_at"] = nil
+	}
+
+	if len(updates) > 0 {
+		if err := h.DB.Model(&user).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+			return
+		}
+	}
+
+	if _, changed := updates["email"]; changed {
+		if err := sendVerificationEmail(h.DB, h.Mailer, &user); err != nil {
+			log.Printf("Failed to send verification email for user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUser - публичный профиль пользователя (без email)
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.PublicUser
+	if err := h.DB.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	stats, err := h.userStats(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":  user,
+		"stats": stats,
+	})
+}
+
+// GetUserQuotes - цитаты пользователя с пагинацией
+func (h *UserHandler) GetUserQuotes(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	params, errs := parseListParams(c, listOptions{SortFields: quoteSortFields, DefaultSort: "created_at"})
+	if len(errs) > 0 {
+		respondQueryErrors(c, errs)
+		return
+	}
+
+	var user models.PublicUser
+	if err := h.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	query := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).Where("user_id = ?", user.ID)
+
+	var total int64
+	query.Count(&total)
+
+	var quotes []models.Quote
+	if err := query.Preload("User").Preload("Category").Preload("Tags").
+		Order(params.OrderClause()).
+		Offset(params.Offset()).Limit(params.Limit).
+		Find(&quotes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotes":     quotes,
+		"pagination": paginationMeta(params.Page, params.Limit, total),
+	})
+}
+
+// userStats считает цитаты, полученные лайки, комментарии и подписки пользователя
+func (h *UserHandler) userStats(userID uint) (models.UserStats, error) {
+	var stats models.UserStats
+
+	if err := h.DB.Model(&models.Quote{}).Scopes(visibleQuotes).
+		Select("COUNT(*) AS quotes_count, COALESCE(SUM(likes_count), 0) AS likes_received").
+		Where("user_id = ?", userID).
+		Scan(&stats).Error; err != nil {
+		return stats, err
+	}
+
+	if err := h.DB.Model(&models.Comment{}).
+		Where("user_id = ?", userID).
+		Count(&stats.CommentsCount).Error; err != nil {
+		return stats, err
+	}
+
+	if err := h.DB.Model(&models.UserFollow{}).
+		Where("followee_id = ?", userID).
+		Count(&stats.FollowersCount).Error; err != nil {
+		return stats, err
+	}
+
+	if err := h.DB.Model(&models.UserFollow{}).
+		Where("follower_id = ?", userID).
+		Count(&stats.FollowingCount).Error; err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// fieldTaken проверяет, занято ли значение уникального поля другим пользователем
+func (h *UserHandler) fieldTaken(field, value string, exceptID uint) bool {
+	var count int64
+	h.DB.Model(&models.User{}).Where(field+" = ? AND id <> ?", value, exceptID).Count(&count)
+	return count > 0
+}
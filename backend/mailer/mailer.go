@@ -0,0 +1,13 @@
+package mailer
+
+// Message - простое текстовое письмо
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма; реализации - SMTP и лог/файл для локальной разработки
+type Mailer interface {
+	Send(msg Message) error
+}
@@ -6,6 +6,7 @@ import (
 	"quotes-app/config"
 	"quotes-app/handlers"
 	"quotes-app/middleware"
+	"quotes-app/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +15,14 @@ func main() {
 	// Подключение к БД и JWT
 	config.ConnectDatabase()
 	config.InitJWT()
+	config.InitMailer()
+	config.InitLoginGuard()
+	config.InitRateLimiter()
+	config.InitDailyQuote()
+	config.InitRealtime()
+	config.InitModeration()
+	config.InitContentFilter()
+	config.InitTrash()
 
 	log.Println("Database connected successfully. Using SQL migrations.")
 
@@ -24,6 +33,7 @@ func main() {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -38,37 +48,163 @@ func main() {
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 
+	// Общий лимит запросов с одного IP
+	router.Use(middleware.RateLimit("global"))
+
 	// Handlers
 	authHandler := handlers.NewAuthHandler()
 	quoteHandler := handlers.NewQuoteHandler()
 	categoryHandler := handlers.NewCategoryHandler()
 	commentHandler := handlers.NewCommentHandler()
+	userHandler := handlers.NewUserHandler()
+	searchHandler := handlers.NewSearchHandler()
+	dailyQuoteHandler := handlers.NewDailyQuoteHandler()
+	tagHandler := handlers.NewTagHandler()
+	authorHandler := handlers.NewAuthorHandler()
+	collectionHandler := handlers.NewCollectionHandler()
+	followHandler := handlers.NewFollowHandler()
+	notificationHandler := handlers.NewNotificationHandler()
+	streamHandler := handlers.NewStreamHandler()
+	reportHandler := handlers.NewReportHandler()
+	duplicateHandler := handlers.NewDuplicateHandler()
+	trashHandler := handlers.NewTrashHandler()
 
 	// --- Публичные роуты ---
-	router.POST("/register", authHandler.Register)
-	router.POST("/login", authHandler.Login)
+	authLimit := middleware.RateLimit("auth")
+	router.POST("/register", authLimit, authHandler.Register)
+	router.POST("/login", authLimit, authHandler.Login)
+	router.POST("/token/refresh", authLimit, authHandler.RefreshToken)
+	router.POST("/password/forgot", authLimit, authHandler.ForgotPassword)
+	router.POST("/password/reset", authLimit, authHandler.ResetPassword)
+	router.GET("/verify-email", authHandler.VerifyEmail)
 
 	router.GET("/quotes", quoteHandler.GetQuotes)
-	router.GET("/quotes/:id", quoteHandler.GetQuoteByID)
+	router.GET("/quotes/daily", dailyQuoteHandler.GetDailyQuote)
+	router.GET("/quotes/random", quoteHandler.GetRandomQuotes)
+	router.GET("/quotes/:id", middleware.OptionalAuthMiddleware(), quoteHandler.GetQuoteByID)
+	router.GET("/quotes/:id/citation", middleware.OptionalAuthMiddleware(), quoteHandler.GetQuoteCitation)
+	router.GET("/search", searchHandler.Search)
+	router.GET("/stream", streamHandler.Stream)
+	router.GET("/ws", streamHandler.WebSocket)
+	router.GET("/users/:id", userHandler.GetUser)
+	router.GET("/users/:id/quotes", userHandler.GetUserQuotes)
+	router.GET("/users/:id/collections", collectionHandler.GetUserCollections)
+	router.GET("/users/:id/followers", followHandler.GetFollowers)
+	router.GET("/users/:id/following", followHandler.GetFollowing)
+	router.GET("/collections/:id", middleware.OptionalAuthMiddleware(), collectionHandler.GetCollection)
+	router.GET("/tags", tagHandler.GetTags)
+	router.GET("/authors", authorHandler.GetAuthors)
+	router.GET("/authors/:id", authorHandler.GetAuthorByID)
 	router.GET("/categories", categoryHandler.GetCategories)
-	router.GET("/quotes/:id/comments", commentHandler.GetComments)
+	router.GET("/categories/:id", categoryHandler.GetCategoryByID)
+	router.GET("/categories/:id/quotes", categoryHandler.GetCategoryQuotes)
+	router.GET("/quotes/:id/comments", middleware.OptionalAuthMiddleware(), commentHandler.GetComments)
+	router.GET("/comments/:id/replies", middleware.OptionalAuthMiddleware(), commentHandler.GetReplies)
 
 	// --- Защищённые роуты (нужен JWT) ---
 	auth := router.Group("/")
-	auth.Use(middleware.AuthMiddleware())
+	auth.Use(middleware.AuthMiddleware(), middleware.RateLimit("user"))
 	{
-		// Цитаты
-		auth.POST("/quotes", quoteHandler.CreateQuote)
+		quoteLimit := middleware.RateLimit("quotes")
+		commentLimit := middleware.RateLimit("comments")
+		reactionLimit := middleware.RateLimit("reactions")
+
+		// Сессии
+		auth.POST("/logout", authHandler.Logout)
+		auth.POST("/logout-all", authHandler.LogoutAll)
+
+		// Профиль
+		auth.GET("/me", userHandler.GetMe)
+		auth.PUT("/me", userHandler.UpdateMe)
+		auth.POST("/me/password", authLimit, authHandler.ChangePassword)
+		auth.POST("/verify-email/resend", authLimit, authHandler.ResendVerification)
+
+		// Цитаты (публикация - только с подтвержденным email)
+		auth.POST("/quotes", quoteLimit, middleware.RequireVerifiedEmail(), quoteHandler.CreateQuote)
 		auth.PUT("/quotes/:id", quoteHandler.UpdateQuote)
 		auth.DELETE("/quotes/:id", quoteHandler.DeleteQuote)
-		auth.POST("/quotes/:id/like", quoteHandler.LikeQuote)
-		auth.POST("/quotes/:id/dislike", quoteHandler.DislikeQuote)
+		auth.POST("/quotes/:id/restore", quoteHandler.RestoreQuote)
+		auth.POST("/quotes/:id/like", reactionLimit, quoteHandler.LikeQuote)
+		auth.POST("/quotes/:id/dislike", reactionLimit, quoteHandler.DislikeQuote)
 
 		// Комментарии
-		auth.POST("/quotes/:id/comments", commentHandler.AddComment)
-		auth.POST("/comments/:id/like", commentHandler.LikeComment)
+		auth.POST("/quotes/:id/comments", commentLimit, middleware.RequireVerifiedEmail(), commentHandler.AddComment)
+		auth.POST("/comments/:id/replies", commentLimit, middleware.RequireVerifiedEmail(), commentHandler.AddReply)
+		auth.POST("/comments/:id/like", reactionLimit, commentHandler.LikeComment)
 		auth.PUT("/comments/:id", commentHandler.UpdateComment)
 		auth.DELETE("/comments/:id", commentHandler.DeleteComment)
+		auth.POST("/comments/:id/restore", commentHandler.RestoreComment)
+
+		// Корзина
+		auth.GET("/me/trash", trashHandler.GetTrash)
+
+		// Жалобы
+		auth.POST("/quotes/:id/report", reportHandler.ReportQuote)
+		auth.POST("/comments/:id/report", reportHandler.ReportComment)
+
+		// Подписки и лента
+		auth.POST("/users/:id/follow", followHandler.FollowUser)
+		auth.DELETE("/users/:id/follow", followHandler.UnfollowUser)
+		auth.POST("/categories/:id/follow", followHandler.FollowCategory)
+		auth.DELETE("/categories/:id/follow", followHandler.UnfollowCategory)
+		auth.GET("/me/following/categories", followHandler.GetFollowedCategories)
+		auth.GET("/feed", followHandler.GetFeed)
+
+		// Уведомления
+		auth.GET("/notifications", notificationHandler.GetNotifications)
+		auth.GET("/notifications/unread-count", notificationHandler.GetUnreadCount)
+		auth.POST("/notifications/read", notificationHandler.MarkRead)
+		auth.GET("/notifications/preferences", notificationHandler.GetPreferences)
+		auth.PUT("/notifications/preferences", notificationHandler.UpdatePreferences)
+
+		// Коллекции
+		auth.GET("/me/collections", collectionHandler.GetMyCollections)
+		auth.POST("/collections", collectionHandler.CreateCollection)
+		auth.PUT("/collections/:id", collectionHandler.UpdateCollection)
+		auth.DELETE("/collections/:id", collectionHandler.DeleteCollection)
+		auth.POST("/collections/:id/quotes", collectionHandler.AddQuote)
+		auth.PUT("/collections/:id/quotes/order", collectionHandler.ReorderQuotes)
+		auth.DELETE("/collections/:id/quotes/:quote_id", collectionHandler.RemoveQuote)
+	}
+
+	// --- Администрирование (только admin) ---
+	admin := router.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/users", userHandler.ListUsers)
+		admin.PUT("/users/:id/role", userHandler.UpdateUserRole)
+		admin.DELETE("/users/:id", userHandler.DeleteUser)
+		admin.POST("/users/:id/unlock", userHandler.UnlockUser)
+		admin.GET("/duplicates", duplicateHandler.GetDuplicates)
+	}
+
+	// --- Модерация жалоб (moderator и admin) ---
+	moderation := router.Group("/moderation")
+	moderation.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleModerator, models.RoleAdmin))
+	{
+		moderation.GET("/reports", reportHandler.GetReports)
+		moderation.POST("/reports/:id/resolve", reportHandler.ResolveReport)
+		moderation.POST("/reports/:id/dismiss", reportHandler.DismissReport)
+		moderation.GET("/filter-log", reportHandler.GetFilterLog)
+	}
+
+	// --- Управление категориями (только admin) ---
+	categoryAdmin := router.Group("/categories")
+	categoryAdmin.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin))
+	{
+		categoryAdmin.POST("", categoryHandler.CreateCategory)
+		categoryAdmin.PUT("/:id", categoryHandler.UpdateCategory)
+		categoryAdmin.DELETE("/:id", categoryHandler.DeleteCategory)
+		categoryAdmin.POST("/:id/merge", categoryHandler.MergeCategory)
+	}
+
+	// --- Управление авторами (только admin) ---
+	authorAdmin := router.Group("/authors")
+	authorAdmin.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin))
+	{
+		authorAdmin.POST("", authorHandler.CreateAuthor)
+		authorAdmin.PUT("/:id", authorHandler.UpdateAuthor)
+		authorAdmin.POST("/:id/merge", authorHandler.MergeAuthor)
 	}
 
 	// Health check
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"quotes-app/config"
+	"quotes-app/models"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+var errSessionRevoked = errors.New("session revoked")
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -18,14 +23,20 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		claims, err := config.ValidateToken(tokenString)
+		claims, err := authenticate(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			if errors.Is(err, errSessionRevoked) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			} else {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			}
 			c.Abort()
 			return
 		}
 
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
+		c.Set("role", claims.Role)
 		c.Next()
 	}
 }
@@ -35,10 +46,70 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
 			tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-			if claims, err := config.ValidateToken(tokenString); err == nil {
+			if claims, err := authenticate(tokenString); err == nil {
 				c.Set("user_id", claims.UserID)
+				c.Set("session_id", claims.SessionID)
+				c.Set("role", claims.Role)
 			}
 		}
 		c.Next()
 	}
 }
+
+// RequireRole пропускает только пользователей с одной из перечисленных ролей.
+// Должен стоять после AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// RequireVerifiedEmail пропускает только пользователей с подтвержденным email.
+// Должен стоять после AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := config.DB.Select("id", "email_verified_at").First(&user, c.GetUint("user_id")).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email is not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticate проверяет подпись токена и то, что его сессия все еще активна
+func authenticate(tokenString string) (*config.Claims, error) {
+	claims, err := config.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ?", claims.SessionID, claims.UserID).
+		First(&session).Error; err != nil {
+		return nil, errSessionRevoked
+	}
+
+	if !session.IsActive(time.Now()) {
+		return nil, errSessionRevoked
+	}
+
+	return claims, nil
+}
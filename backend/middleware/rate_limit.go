@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"quotes-app/config"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit ограничивает частоту запросов по лимиту группы из config.RateLimits.
+// Авторизованные запросы считаются по user_id (если до этого отработал AuthMiddleware), остальные - по IP.
+func RateLimit(group string) gin.HandlerFunc {
+	rate, ok := config.RateLimits[group]
+	if !ok {
+		panic("unknown rate limit group: " + group)
+	}
+
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if userID, exists := c.Get("user_id"); exists {
+			key = group + ":user:" + strconv.FormatUint(uint64(userID.(uint)), 10)
+		}
+
+		result, err := config.RateLimiter.Allow(key, rate)
+		if err != nil {
+			// Сбой хранилища не должен ронять API - пропускаем запрос
+			log.Printf("Rate limiter error for %s: %v", key, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Rate limit exceeded",
+				"retry_after": retryAfter,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Author - автор цитат. Quote.Author хранит каноническое имя для совместимости,
+// связь с автором - Quote.AuthorID
+type Author struct {
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	Name        string        `gorm:"size:100;not null" json:"name"`
+	NameKey     string        `gorm:"uniqueIndex;size:100;not null" json:"-"`
+	Bio         string        `gorm:"type:text" json:"bio"`
+	BirthYear   *int          `json:"birth_year"`
+	DeathYear   *int          `json:"death_year"`
+	Aliases     []AuthorAlias `gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE;" json:"aliases,omitempty"`
+	QuotesCount int64         `gorm:"->;-:migration" json:"quotes_count"`
+	CreatedAt   time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// AuthorAlias - другое написание имени автора ("Einstein", "А. Эйнштейн")
+type AuthorAlias struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	AuthorID uint   `gorm:"not null" json:"author_id"`
+	Name     string `gorm:"size:100;not null" json:"name"`
+	NameKey  string `gorm:"uniqueIndex;size:100;not null" json:"-"`
+}
+
+// AuthorStats - статистика страницы автора
+type AuthorStats struct {
+	QuotesCount   int64 `json:"quotes_count"`
+	LikesCount    int64 `json:"likes_count"`
+	DislikesCount int64 `json:"dislikes_count"`
+}
+
+// AuthorCreateRequest для валидации при создании
+type AuthorCreateRequest struct {
+	Name      string   `json:"name" binding:"required,min=1,max=100"`
+	Bio       string   `json:"bio" binding:"max=2000"`
+	BirthYear *int     `json:"birth_year"`
+	DeathYear *int     `json:"death_year"`
+	Aliases   []string `json:"aliases" binding:"omitempty,max=20,dive,min=1,max=100"`
+}
+
+type AuthorUpdateRequest struct {
+	Name      string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Bio       *string `json:"bio" binding:"omitempty,max=2000"`
+	BirthYear *int    `json:"birth_year"`
+	DeathYear *int    `json:"death_year"`
+	// Aliases: nil - не менять, пустой список - удалить все псевдонимы
+	Aliases *[]string `json:"aliases" binding:"omitempty,max=20,dive,min=1,max=100"`
+}
+
+// AuthorMergeRequest - все цитаты и псевдонимы переносятся к целевому автору
+type AuthorMergeRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
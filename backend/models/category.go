@@ -6,3 +6,19 @@ type Category struct {
 	Description string  `gorm:"type:text" json:"description"`
 	Quotes      []Quote `gorm:"foreignKey:CategoryID" json:"quotes,omitempty"`
 }
+
+// CategoryCreateRequest для валидации при создании
+type CategoryCreateRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+type CategoryUpdateRequest struct {
+	Name        string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// CategoryMergeRequest - все цитаты переносятся в целевую категорию
+type CategoryMergeRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Видимость коллекции
+const (
+	CollectionPrivate = "private"
+	CollectionPublic  = "public"
+)
+
+type Collection struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null" json:"user_id"`
+	User        PublicUser `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	Visibility  string     `gorm:"size:10;not null;default:private" json:"visibility"`
+	QuotesCount int64      `gorm:"->;-:migration" json:"quotes_count"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CollectionQuote - цитата в коллекции и ее место в пользовательском порядке
+type CollectionQuote struct {
+	CollectionID uint      `gorm:"primaryKey" json:"collection_id"`
+	QuoteID      uint      `gorm:"primaryKey" json:"quote_id"`
+	Position     int       `gorm:"not null" json:"position"`
+	AddedAt      time.Time `gorm:"autoCreateTime" json:"added_at"`
+	Quote        Quote     `gorm:"foreignKey:QuoteID" json:"quote"`
+}
+
+// IsVisibleTo - приватную коллекцию видит только владелец
+func (c *Collection) IsVisibleTo(userID uint) bool {
+	return c.Visibility == CollectionPublic || c.UserID == userID
+}
+
+// CollectionCreateRequest для валидации при создании
+type CollectionCreateRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private public"`
+}
+
+type CollectionUpdateRequest struct {
+	Name        string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=private public"`
+}
+
+// CollectionAddQuoteRequest - position начинается с 1; без position цитата добавляется в конец
+type CollectionAddQuoteRequest struct {
+	QuoteID  uint `json:"quote_id" binding:"required"`
+	Position *int `json:"position" binding:"omitempty,min=1"`
+}
+
+// CollectionReorderRequest - новый порядок всех цитат коллекции
+type CollectionReorderRequest struct {
+	QuoteIDs []uint `json:"quote_ids" binding:"required,dive,required"`
+}
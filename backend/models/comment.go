@@ -1,16 +1,30 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeletedCommentContent - текст заглушки удаленного комментария, у которого есть ответы
+const DeletedCommentContent = "[deleted]"
 
 type Comment struct {
-	ID           uint          `gorm:"primaryKey" json:"id"`
-	Content      string        `gorm:"type:text;not null" json:"content" binding:"required,min=1,max=500"`
-	QuoteID      uint          `gorm:"not null" json:"quote_id"`
-	UserID       *uint         `json:"user_id"`
-	User         User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	LikesCount   int           `gorm:"default:0" json:"likes_count"`
-	CommentLikes []CommentLike `gorm:"foreignKey:CommentID" json:"comment_likes,omitempty"`
-	CreatedAt    time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	Content      string         `gorm:"type:text;not null" json:"content" binding:"required,min=1,max=500"`
+	QuoteID      uint           `gorm:"not null" json:"quote_id"`
+	ParentID     *uint          `gorm:"index" json:"parent_id"`
+	UserID       *uint          `json:"user_id"`
+	User         PublicUser     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	LikesCount   int            `gorm:"default:0" json:"likes_count"`
+	IsDeleted    bool           `gorm:"-" json:"is_deleted"`
+	ReplyCount   int64          `gorm:"->;-:migration" json:"reply_count"`
+	Replies      []*Comment     `gorm:"-" json:"replies,omitempty"`
+	CommentLikes []CommentLike  `gorm:"foreignKey:CommentID" json:"comment_likes,omitempty"`
+	HiddenAt     *time.Time     `json:"hidden_at,omitempty"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedBy    *uint          `json:"-"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
 }
 
 type CommentCreateRequest struct {
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ContentFilterLog - запись журнала фильтра контента: какой фильтр сработал и что он сделал.
+// Content - исходный текст до маскирования
+type ContentFilterLog struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	UserID     *uint       `json:"user_id"`
+	User       *PublicUser `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TargetType string      `gorm:"size:10;not null" json:"target_type"`
+	QuoteID    *uint       `json:"quote_id"`
+	CommentID  *uint       `json:"comment_id"`
+	Filter     string      `gorm:"size:30;not null" json:"filter"`
+	Action     string      `gorm:"size:10;not null" json:"action"`
+	Reason     string      `gorm:"size:255;not null" json:"reason"`
+	Terms      *string     `gorm:"type:text" json:"terms"`
+	Content    string      `gorm:"type:text;not null" json:"content"`
+	CreatedAt  time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DailyQuote - цитата дня; CategoryID == nil означает выбор по всем категориям
+type DailyQuote struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	QuoteDate  time.Time `gorm:"type:date;not null" json:"quote_date"`
+	CategoryID *uint     `json:"category_id"`
+	QuoteID    uint      `gorm:"not null" json:"quote_id"`
+	Quote      Quote     `gorm:"foreignKey:QuoteID" json:"quote,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// UserFollow - подписка follower на followee
+type UserFollow struct {
+	FollowerID uint      `gorm:"primaryKey" json:"follower_id"`
+	FolloweeID uint      `gorm:"primaryKey" json:"followee_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// CategoryFollow - подписка пользователя на категорию
+type CategoryFollow struct {
+	UserID     uint      `gorm:"primaryKey" json:"user_id"`
+	CategoryID uint      `gorm:"primaryKey" json:"category_id"`
+	Category   Category  `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Типы уведомлений
+const (
+	NotificationQuoteLike    = "quote_like"
+	NotificationQuoteComment = "quote_comment"
+	NotificationCommentReply = "comment_reply"
+	NotificationCommentLike  = "comment_like"
+)
+
+var NotificationTypes = []string{
+	NotificationQuoteLike,
+	NotificationQuoteComment,
+	NotificationCommentReply,
+	NotificationCommentLike,
+}
+
+// Notification - уведомление получателя UserID; однотипные события по одному объекту
+// собираются в одно уведомление, ActorCount - сколько разных пользователей в нем
+type Notification struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	UserID      uint        `gorm:"not null" json:"-"`
+	Type        string      `gorm:"size:30;not null" json:"type"`
+	GroupKey    string      `gorm:"size:100;not null" json:"-"`
+	QuoteID     *uint       `json:"quote_id"`
+	CommentID   *uint       `json:"comment_id"`
+	LastActorID *uint       `json:"-"`
+	LastActor   *PublicUser `gorm:"foreignKey:LastActorID" json:"last_actor"`
+	ActorCount  int         `gorm:"not null;default:0" json:"actor_count"`
+	Message     string      `gorm:"-" json:"message"`
+	ReadAt      *time.Time  `json:"read_at"`
+	CreatedAt   time.Time   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time   `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NotificationPreference - включен ли тип уведомлений у пользователя
+type NotificationPreference struct {
+	UserID  uint   `gorm:"primaryKey"`
+	Type    string `gorm:"primaryKey;size:30"`
+	Enabled bool   `gorm:"not null"`
+}
+
+// NotificationEvent - событие, о котором нужно уведомить владельца контента
+type NotificationEvent struct {
+	RecipientID *uint
+	ActorID     uint
+	Type        string
+	QuoteID     *uint
+	CommentID   *uint
+	// GroupKey - события с одинаковым ключом схлопываются в одно уведомление
+	GroupKey string
+}
+
+// NotificationReadRequest - отметить прочитанными конкретные уведомления или все сразу
+type NotificationReadRequest struct {
+	IDs []uint `json:"ids" binding:"omitempty,max=100"`
+	All bool   `json:"all"`
+}
@@ -1,32 +1,57 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Quote struct {
-	ID            uint        `gorm:"primaryKey" json:"id"`
-	Content       string      `gorm:"type:text;not null" json:"content" binding:"required,min=1,max=1000"`
-	Author        string      `gorm:"size:100" json:"author" binding:"required,min=1,max=100"`
-	UserID        *uint       `json:"user_id"`
-	User          User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	CategoryID    *uint       `json:"category_id" binding:"required"`
-	Category      Category    `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	LikesCount    int         `gorm:"default:0" json:"likes_count"`
-	DislikesCount int         `gorm:"default:0" json:"dislikes_count"`
-	Comments      []Comment   `gorm:"foreignKey:QuoteID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
-	QuoteLikes    []QuoteLike `gorm:"foreignKey:QuoteID;constraint:OnDelete:CASCADE;" json:"quote_likes,omitempty"`
-	CreatedAt     time.Time   `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt     time.Time   `gorm:"autoUpdateTime" json:"updated_at"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Content       string         `gorm:"type:text;not null" json:"content" binding:"required,min=1,max=1000"`
+	Author        string         `gorm:"size:100" json:"author" binding:"required,min=1,max=100"`
+	AuthorID      *uint          `json:"author_id"`
+	UserID        *uint          `json:"user_id"`
+	User          PublicUser     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CategoryID    *uint          `json:"category_id" binding:"required"`
+	Category      Category       `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Tags          []Tag          `gorm:"many2many:quote_tags;" json:"tags,omitempty"`
+	Source        QuoteSource    `gorm:"embedded;embeddedPrefix:source_" json:"source"`
+	LikesCount    int            `gorm:"default:0" json:"likes_count"`
+	DislikesCount int            `gorm:"default:0" json:"dislikes_count"`
+	Comments      []Comment      `gorm:"foreignKey:QuoteID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
+	QuoteLikes    []QuoteLike    `gorm:"foreignKey:QuoteID;constraint:OnDelete:CASCADE;" json:"quote_likes,omitempty"`
+	HiddenAt      *time.Time     `json:"hidden_at,omitempty"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedBy     *uint          `json:"-"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// QuoteSource - откуда взята цитата: книга, речь, статья и т.п. Все поля необязательны
+type QuoteSource struct {
+	Type  *string `gorm:"size:20" json:"type" binding:"omitempty,oneof=book speech article interview letter film website other"`
+	Title *string `gorm:"size:255" json:"title" binding:"omitempty,min=1,max=255"`
+	Year  *int    `json:"year" binding:"omitempty,min=-3000,max=2100"`
+	Page  *string `gorm:"size:20" json:"page" binding:"omitempty,min=1,max=20"`
+	URL   *string `gorm:"size:500" json:"url" binding:"omitempty,url,max=500"`
 }
 
 // QuoteCreateRequest для валидации при создании
 type QuoteCreateRequest struct {
-	Content    string `json:"content" binding:"required,min=1,max=1000"`
-	Author     string `json:"author" binding:"required,min=1,max=100"`
-	CategoryID uint   `json:"category_id" binding:"required"`
+	Content    string       `json:"content" binding:"required,min=1,max=1000"`
+	Author     string       `json:"author" binding:"required,min=1,max=100"`
+	CategoryID uint         `json:"category_id" binding:"required"`
+	Tags       []string     `json:"tags" binding:"omitempty,max=10,dive,min=1,max=50"`
+	Source     *QuoteSource `json:"source"`
 }
 
 type QuoteUpdateRequest struct {
 	Content    string `json:"content" binding:"omitempty,min=1,max=1000"`
 	Author     string `json:"author" binding:"omitempty,min=1,max=100"`
 	CategoryID uint   `json:"category_id" binding:"omitempty"`
+	// Tags: nil - не менять, пустой список - снять все теги
+	Tags *[]string `json:"tags" binding:"omitempty,max=10,dive,min=1,max=50"`
+	// Source: nil - не менять, иначе источник заменяется целиком ({} - очистить)
+	Source *QuoteSource `json:"source"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Объекты жалоб
+const (
+	ReportTargetQuote   = "quote"
+	ReportTargetComment = "comment"
+)
+
+// Статусы жалоб
+const (
+	ReportPending   = "pending"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// Report - жалоба пользователя на цитату или комментарий
+type Report struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	ReporterID     *uint       `json:"reporter_id"`
+	Reporter       *PublicUser `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
+	TargetType     string      `gorm:"size:10;not null" json:"target_type"`
+	QuoteID        *uint       `json:"quote_id,omitempty"`
+	Quote          *Quote      `gorm:"foreignKey:QuoteID" json:"quote,omitempty"`
+	CommentID      *uint       `json:"comment_id,omitempty"`
+	Comment        *Comment    `gorm:"foreignKey:CommentID" json:"comment,omitempty"`
+	Reason         string      `gorm:"size:20;not null" json:"reason"`
+	Details        *string     `gorm:"type:text" json:"details"`
+	Status         string      `gorm:"size:10;not null;default:pending" json:"status"`
+	ResolvedBy     *uint       `json:"resolved_by"`
+	ResolvedAt     *time.Time  `json:"resolved_at"`
+	ResolutionNote *string     `gorm:"type:text" json:"resolution_note"`
+	CreatedAt      time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ReportCreateRequest - жалоба с кодом причины
+type ReportCreateRequest struct {
+	Reason  string `json:"reason" binding:"required,oneof=spam offensive harassment misinformation copyright other"`
+	Details string `json:"details" binding:"omitempty,max=1000"`
+}
+
+// ReportReviewRequest - решение модератора по жалобе
+type ReportReviewRequest struct {
+	Note string `json:"note" binding:"omitempty,max=1000"`
+}
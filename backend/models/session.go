@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Session - серверная сессия, к которой привязан refresh токен
+type Session struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	PreviousTokenHash *string    `gorm:"size:64" json:"-"`
+	UserAgent         string     `gorm:"size:255" json:"user_agent"`
+	IPAddress         string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	LastUsedAt        time.Time  `gorm:"autoCreateTime" json:"last_used_at"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// IsActive проверяет, что сессия не отозвана и не истекла
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshTokenRequest для обновления пары токенов
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Tag - тег цитаты; Slug - нормализованное имя, по нему теги сравниваются
+type Tag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:50;not null" json:"name"`
+	Slug        string    `gorm:"uniqueIndex;size:50;not null" json:"slug"`
+	QuotesCount int64     `gorm:"->;-:migration" json:"quotes_count,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -7,13 +7,55 @@ import (
 )
 
 type User struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	Username     string    `gorm:"uniqueIndex;not null;size:50" json:"username" binding:"required,min=3,max=50"`
-	Email        string    `gorm:"uniqueIndex;not null;size:100" json:"email" binding:"required,email"`
-	PasswordHash string    `gorm:"not null;size:255" json:"-"`
-	Quotes       []Quote   `gorm:"foreignKey:UserID" json:"quotes,omitempty"`
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Username        string     `gorm:"uniqueIndex;not null;size:50" json:"username" binding:"required,min=3,max=50"`
+	Email           string     `gorm:"uniqueIndex;not null;size:100" json:"email" binding:"required,email"`
+	PasswordHash    string     `gorm:"not null;size:255" json:"-"`
+	Role            string     `gorm:"not null;size:20;default:user" json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Quotes          []Quote    `gorm:"foreignKey:UserID" json:"quotes,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PublicUser - публичное представление пользователя без email.
+// Используется в связях цитат и комментариев, чтобы email не попадал в публичные ответы.
+type PublicUser struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PublicUser) TableName() string {
+	return "users"
+}
+
+// UserStats - агрегированная статистика профиля
+type UserStats struct {
+	QuotesCount    int64 `json:"quotes_count"`
+	LikesReceived  int64 `json:"likes_received"`
+	CommentsCount  int64 `json:"comments_count"`
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// UserProfileUpdateRequest для изменения своего профиля
+type UserProfileUpdateRequest struct {
+	Username string `json:"username" binding:"omitempty,min=3,max=50"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
+
+// Роли пользователей
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// UserRoleUpdateRequest для смены роли администратором
+type UserRoleUpdateRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
 }
 
 // UserRegisterRequest для валидации при регистрации
@@ -38,6 +80,16 @@ func (u *User) SetPassword(password string) error {
 	return nil
 }
 
+// IsModeratorRole - модераторы и администраторы могут править чужой контент
+func IsModeratorRole(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// IsEmailVerified - подтвердил ли пользователь email
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 func (u *User) CheckPassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
 }
@@ -0,0 +1,205 @@
+// Package realtime - pub/sub хаб для событий о цитатах, комментариях и реакциях.
+// Хаб раздает события локальным подпискам (SSE и WebSocket), а Broker доставляет
+// опубликованные события во все экземпляры приложения.
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Топики подписки
+const (
+	// TopicQuotes - все новые цитаты и реакции на них
+	TopicQuotes = "quotes"
+)
+
+// QuoteTopic - комментарии и реакции одной цитаты
+func QuoteTopic(quoteID uint) string {
+	return "quote:" + strconv.FormatUint(uint64(quoteID), 10)
+}
+
+// CategoryTopic - новые цитаты и реакции в категории
+func CategoryTopic(categoryID uint) string {
+	return "category:" + strconv.FormatUint(uint64(categoryID), 10)
+}
+
+// ErrInvalidTopic - топик не из списка поддерживаемых
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// ParseTopic проверяет топик, присланный клиентом: quotes, quote:<id> или category:<id>
+func ParseTopic(topic string) (string, error) {
+	if topic == TopicQuotes {
+		return topic, nil
+	}
+
+	kind, rawID, found := strings.Cut(topic, ":")
+	if !found || (kind != "quote" && kind != "category") {
+		return "", ErrInvalidTopic
+	}
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil || id == 0 {
+		return "", ErrInvalidTopic
+	}
+
+	return kind + ":" + strconv.FormatUint(id, 10), nil
+}
+
+// Типы событий
+const (
+	EventQuoteCreated   = "quote.created"
+	EventQuoteReaction  = "quote.reaction"
+	EventCommentCreated = "comment.created"
+	EventCommentLiked   = "comment.liked"
+)
+
+// Event - событие, которое получают подписчики хотя бы одного из Topics
+type Event struct {
+	Type   string          `json:"type"`
+	Topics []string        `json:"topics"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Broker доставляет событие всем экземплярам приложения, включая текущий
+type Broker interface {
+	Publish(event Event) error
+}
+
+// subscriptionBuffer - сколько событий может накопиться у медленного клиента
+const subscriptionBuffer = 64
+
+// Subscription - подписка одного клиента. События приходят в канал Events;
+// канал закрывается, если клиент не успевает их читать или хаб отписал его.
+type Subscription struct {
+	Events <-chan Event
+
+	events chan Event
+	mu     sync.Mutex
+	topics map[string]bool
+	closed bool
+}
+
+// SetTopics заменяет набор топиков подписки
+func (s *Subscription) SetTopics(topics []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.topics = make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		s.topics[topic] = true
+	}
+}
+
+// Topics - текущий набор топиков
+func (s *Subscription) Topics() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func (s *Subscription) matches(event Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, topic := range event.Topics {
+		if s.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver отправляет событие без блокировки; переполненную подписку закрывает
+func (s *Subscription) deliver(event Event) bool {
+	select {
+	case s.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// Hub хранит локальные подписки и публикует события через брокер
+type Hub struct {
+	Broker Broker
+
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewHub создает хаб. Брокер задается отдельно, потому что ему нужен сам хаб для доставки.
+func NewHub() *Hub {
+	return &Hub{subscriptions: make(map[*Subscription]struct{})}
+}
+
+// Subscribe создает подписку на топики
+func (h *Hub) Subscribe(topics []string) *Subscription {
+	events := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{Events: events, events: events}
+	sub.SetTopics(topics)
+
+	h.mu.Lock()
+	h.subscriptions[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe удаляет подписку и закрывает ее канал; повторный вызов безопасен
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	delete(h.subscriptions, sub)
+	h.mu.Unlock()
+
+	sub.mu.Lock()
+	if !sub.closed {
+		sub.closed = true
+		close(sub.events)
+	}
+	sub.mu.Unlock()
+}
+
+// Publish отправляет событие через брокер. Ошибки только логируются:
+// realtime не должен ломать основное действие пользователя. Безопасен для nil хаба.
+func (h *Hub) Publish(eventType string, topics []string, data interface{}) {
+	if h == nil || h.Broker == nil {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	if err := h.Broker.Publish(Event{Type: eventType, Topics: topics, Data: payload}); err != nil {
+		log.Printf("Failed to publish %s event: %v", eventType, err)
+	}
+}
+
+// Dispatch раздает событие локальным подпискам; вызывается брокером
+func (h *Hub) Dispatch(event Event) {
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for sub := range h.subscriptions {
+		if sub.matches(event) && !sub.deliver(event) {
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	// Клиент, который не успевает читать, отключаем: он переподключится и догрузит данные
+	for _, sub := range slow {
+		h.Unsubscribe(sub)
+	}
+}
@@ -0,0 +1,15 @@
+package realtime
+
+// MemoryBroker доставляет события только в текущий экземпляр приложения
+type MemoryBroker struct {
+	Hub *Hub
+}
+
+func NewMemoryBroker(hub *Hub) *MemoryBroker {
+	return &MemoryBroker{Hub: hub}
+}
+
+func (b *MemoryBroker) Publish(event Event) error {
+	b.Hub.Dispatch(event)
+	return nil
+}
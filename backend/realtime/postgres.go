@@ -0,0 +1,94 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// PostgresChannel - канал LISTEN/NOTIFY для событий
+const PostgresChannel = "quotes_events"
+
+// maxNotifyPayload - лимит Postgres на размер payload в NOTIFY (8000 байт)
+const maxNotifyPayload = 7900
+
+var errPayloadTooLarge = errors.New("event payload exceeds NOTIFY limit")
+
+// PostgresBroker рассылает события через NOTIFY, а отдельное соединение с LISTEN
+// доставляет их в хаб. Свои события экземпляр тоже получает через LISTEN.
+type PostgresBroker struct {
+	DB  *gorm.DB
+	Hub *Hub
+	DSN string
+}
+
+func NewPostgresBroker(db *gorm.DB, hub *Hub, dsn string) *PostgresBroker {
+	return &PostgresBroker{DB: db, Hub: hub, DSN: dsn}
+}
+
+func (b *PostgresBroker) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return errPayloadTooLarge
+	}
+
+	return b.DB.Exec("SELECT pg_notify(?, ?)", PostgresChannel, string(payload)).Error
+}
+
+// Listen слушает канал до отмены ctx, переподключаясь после ошибок.
+// Пауза растет только при неудачных подключениях подряд и сбрасывается после успешного LISTEN.
+func (b *PostgresBroker) Listen(ctx context.Context) {
+	const initialBackoff = time.Second
+	backoff := initialBackoff
+	for ctx.Err() == nil {
+		err := b.listen(ctx, func() { backoff = initialBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Realtime listener disconnected: %v; reconnecting in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// listen держит одно подключение; connected вызывается после успешного LISTEN
+func (b *PostgresBroker) listen(ctx context.Context, connected func()) error {
+	conn, err := pgx.Connect(ctx, b.DSN)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+PostgresChannel); err != nil {
+		return err
+	}
+	log.Println("Realtime listener connected")
+	connected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("Invalid realtime event payload: %v", err)
+			continue
+		}
+		b.Hub.Dispatch(event)
+	}
+}
@@ -0,0 +1,75 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Attempts - неудачные попытки по одному ключу (аккаунт или IP)
+type Attempts struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// AttemptStore хранит счетчики неудачных попыток.
+// Счетчик сбрасывается, если с последней неудачи прошло больше window.
+type AttemptStore interface {
+	Get(key string, window time.Duration) (Attempts, error)
+	RecordFailure(key string, now time.Time, window time.Duration) (Attempts, error)
+	Reset(key string) error
+}
+
+// MemoryAttemptStore - хранилище в памяти процесса (по умолчанию, одна реплика)
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+	writes   int
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]Attempts)}
+}
+
+func (s *MemoryAttemptStore) Get(key string, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok || time.Since(attempts.LastFailure) > window {
+		return Attempts{}, nil
+	}
+	return attempts, nil
+}
+
+func (s *MemoryAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[key]
+	if now.Sub(attempts.LastFailure) > window {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailure = now
+	s.attempts[key] = attempts
+
+	// Периодически чистим устаревшие ключи, чтобы map не росла бесконечно
+	s.writes++
+	if s.writes%1000 == 0 {
+		for k, a := range s.attempts {
+			if now.Sub(a.LastFailure) > window {
+				delete(s.attempts, k)
+			}
+		}
+	}
+
+	return attempts, nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
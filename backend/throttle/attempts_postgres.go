@@ -0,0 +1,62 @@
+package throttle
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresAttemptStore хранит счетчики в таблице login_attempts,
+// чтобы блокировки работали одинаково на всех репликах
+type PostgresAttemptStore struct {
+	DB *gorm.DB
+}
+
+func NewPostgresAttemptStore(db *gorm.DB) *PostgresAttemptStore {
+	return &PostgresAttemptStore{DB: db}
+}
+
+type attemptRow struct {
+	Failures      int
+	LastFailureAt time.Time
+}
+
+func (s *PostgresAttemptStore) Get(key string, window time.Duration) (Attempts, error) {
+	var row attemptRow
+	err := s.DB.Raw(
+		"SELECT failures, last_failure_at FROM login_attempts WHERE attempt_key = ? AND last_failure_at >= ?",
+		key, time.Now().Add(-window),
+	).Scan(&row).Error
+	if err != nil {
+		return Attempts{}, err
+	}
+	return Attempts{Failures: row.Failures, LastFailure: row.LastFailureAt}, nil
+}
+
+// RecordFailure атомарно увеличивает счетчик одним upsert
+func (s *PostgresAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (Attempts, error) {
+	var row attemptRow
+	err := s.DB.Raw(`
+		INSERT INTO login_attempts (attempt_key, failures, last_failure_at) VALUES (?, 1, ?)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < ? THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures, last_failure_at`,
+		key, now, now.Add(-window),
+	).Scan(&row).Error
+	if err != nil {
+		return Attempts{}, err
+	}
+	if row.Failures == 0 {
+		return Attempts{}, errors.New("login_attempts upsert returned no row")
+	}
+	return Attempts{Failures: row.Failures, LastFailure: row.LastFailureAt}, nil
+}
+
+func (s *PostgresAttemptStore) Reset(key string) error {
+	return s.DB.Exec("DELETE FROM login_attempts WHERE attempt_key = ?", key).Error
+}
@@ -0,0 +1,120 @@
+package throttle
+
+import (
+	"strings"
+	"time"
+)
+
+// Policy - правила блокировки для одного вида ключей
+type Policy struct {
+	// FreeAttempts - сколько неудач подряд допускается без задержки
+	FreeAttempts int
+	// MaxFailures - после стольких неудач ключ блокируется на LockoutDuration
+	MaxFailures int
+	// BaseDelay удваивается с каждой неудачей после FreeAttempts, но не больше MaxDelay
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	// Window - через сколько после последней неудачи счетчик сбрасывается
+	Window time.Duration
+}
+
+// delay - сколько нужно ждать после failures неудач
+func (p Policy) delay(failures int) time.Duration {
+	if failures >= p.MaxFailures {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay << (failures - p.FreeAttempts - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// retryAfter - оставшееся время блокировки ключа
+func (p Policy) retryAfter(attempts Attempts, now time.Time) time.Duration {
+	if attempts.Failures == 0 {
+		return 0
+	}
+
+	wait := attempts.LastFailure.Add(p.delay(attempts.Failures)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// LoginGuard защищает логин от перебора паролей: считает неудачи по аккаунту и по IP
+type LoginGuard struct {
+	Store         AttemptStore
+	AccountPolicy Policy
+	IPPolicy      Policy
+}
+
+func NewLoginGuard(store AttemptStore, accountPolicy, ipPolicy Policy) *LoginGuard {
+	return &LoginGuard{
+		Store:         store,
+		AccountPolicy: accountPolicy,
+		IPPolicy:      ipPolicy,
+	}
+}
+
+// Check возвращает, сколько еще нужно ждать перед следующей попыткой (0 - можно пробовать)
+func (g *LoginGuard) Check(email, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	account, err := g.Store.Get(accountKey(email), g.AccountPolicy.Window)
+	if err != nil {
+		return 0, err
+	}
+	byIP, err := g.Store.Get(ipKey(ip), g.IPPolicy.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(g.AccountPolicy.retryAfter(account, now), g.IPPolicy.retryAfter(byIP, now)), nil
+}
+
+// Fail фиксирует неудачную попытку и возвращает время блокировки, если она наступила
+func (g *LoginGuard) Fail(email, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	account, err := g.Store.RecordFailure(accountKey(email), now, g.AccountPolicy.Window)
+	if err != nil {
+		return 0, err
+	}
+	byIP, err := g.Store.RecordFailure(ipKey(ip), now, g.IPPolicy.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(g.AccountPolicy.retryAfter(account, now), g.IPPolicy.retryAfter(byIP, now)), nil
+}
+
+// Succeed сбрасывает счетчик аккаунта после успешного входа.
+// Счетчик IP не сбрасываем: иначе перебор можно чередовать со входом в свой аккаунт.
+func (g *LoginGuard) Succeed(email string) error {
+	return g.Store.Reset(accountKey(email))
+}
+
+// UnlockAccount снимает блокировку аккаунта (для администратора)
+func (g *LoginGuard) UnlockAccount(email string) error {
+	return g.Store.Reset(accountKey(email))
+}
+
+// UnlockIP снимает блокировку IP адреса (для администратора)
+func (g *LoginGuard) UnlockIP(ip string) error {
+	return g.Store.Reset(ipKey(ip))
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,98 @@
+package throttle
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate - параметры token bucket: до Limit запросов подряд, пополнение Limit токенов за Period
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// perSecond - скорость пополнения токенов
+func (r Rate) perSecond() float64 {
+	return float64(r.Limit) / r.Period.Seconds()
+}
+
+// Result - решение лимитера и данные для заголовков RateLimit-*
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter решает, можно ли пропустить очередной запрос по ключу
+type Limiter interface {
+	Allow(key string, rate Rate) (Result, error)
+}
+
+// newResult считает поля Result по количеству токенов после запроса
+func newResult(allowed bool, tokens float64, rate Rate) Result {
+	perSecond := rate.perSecond()
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      rate.Limit,
+		Remaining:  max(int(math.Floor(tokens)), 0),
+		ResetAfter: time.Duration((float64(rate.Limit) - tokens) / perSecond * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	}
+	return result
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryLimiter - token bucket в памяти процесса (по умолчанию, одна реплика)
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket)}
+}
+
+func (l *MemoryLimiter) Allow(key string, rate Rate) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(rate.Limit)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*rate.perSecond())
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	// Периодически удаляем давно не использованные ключи
+	l.calls++
+	if l.calls%10000 == 0 {
+		for k, other := range l.buckets {
+			if now.Sub(other.updatedAt) > time.Hour {
+				delete(l.buckets, k)
+			}
+		}
+	}
+
+	return newResult(allowed, b.tokens, rate), nil
+}
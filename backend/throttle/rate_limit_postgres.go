@@ -0,0 +1,60 @@
+package throttle
+
+import (
+	"log"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// PostgresLimiter хранит бакеты в таблице rate_limit_buckets, общей для всех реплик.
+// Пополнение и списание токена выполняются одним upsert, время берется из БД.
+type PostgresLimiter struct {
+	DB    *gorm.DB
+	calls atomic.Int64
+}
+
+func NewPostgresLimiter(db *gorm.DB) *PostgresLimiter {
+	return &PostgresLimiter{DB: db}
+}
+
+type bucketRow struct {
+	Tokens  float64
+	Allowed bool
+}
+
+// refillSQL - количество токенов в бакете после пополнения к текущему моменту
+const refillSQL = `LEAST(CAST(@capacity AS DOUBLE PRECISION),
+	b.tokens + EXTRACT(EPOCH FROM (EXCLUDED.updated_at - b.updated_at)) * CAST(@rate AS DOUBLE PRECISION))`
+
+var allowSQL = `
+	INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, allowed, updated_at)
+	VALUES (@key, CAST(@capacity AS DOUBLE PRECISION) - 1, TRUE, LOCALTIMESTAMP)
+	ON CONFLICT (bucket_key) DO UPDATE SET
+		tokens = ` + refillSQL + ` - CASE WHEN ` + refillSQL + ` >= 1 THEN 1 ELSE 0 END,
+		allowed = ` + refillSQL + ` >= 1,
+		updated_at = EXCLUDED.updated_at
+	RETURNING tokens, allowed`
+
+func (l *PostgresLimiter) Allow(key string, rate Rate) (Result, error) {
+	var row bucketRow
+	err := l.DB.Raw(allowSQL, map[string]interface{}{
+		"key":      key,
+		"capacity": float64(rate.Limit),
+		"rate":     rate.perSecond(),
+	}).Scan(&row).Error
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Изредка чистим бакеты, которые давно не использовались
+	if l.calls.Add(1)%10000 == 0 {
+		go func() {
+			if err := l.DB.Exec("DELETE FROM rate_limit_buckets WHERE updated_at < LOCALTIMESTAMP - INTERVAL '1 day'").Error; err != nil {
+				log.Printf("Failed to clean up rate limit buckets: %v", err)
+			}
+		}()
+	}
+
+	return newResult(row.Allowed, row.Tokens, rate), nil
+}
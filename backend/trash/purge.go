@@ -0,0 +1,62 @@
+// Package trash окончательно удаляет цитаты и комментарии, пролежавшие в корзине дольше срока хранения
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxCommentPasses - ограничение проходов по веткам комментариев за одну очистку
+const maxCommentPasses = 100
+
+// Purge удаляет просроченные строки корзины. Удаление цитаты каскадно удаляет ее
+// комментарии и реакции. Комментарий удаляется, только когда у него не осталось ответов
+// (иначе каскад по parent_id унес бы живые ответы), поэтому ветки чистятся с листьев.
+func Purge(db *gorm.DB, retention time.Duration) (quotes, comments int64, err error) {
+	cutoff := time.Now().Add(-retention)
+
+	result := db.Exec("DELETE FROM quotes WHERE deleted_at < ?", cutoff)
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	quotes = result.RowsAffected
+
+	for range maxCommentPasses {
+		result := db.Exec(`DELETE FROM comments
+			WHERE deleted_at < ?
+				AND NOT EXISTS (SELECT 1 FROM comments AS r WHERE r.parent_id = comments.id)`, cutoff)
+		if result.Error != nil {
+			return quotes, comments, result.Error
+		}
+		if result.RowsAffected == 0 {
+			break
+		}
+		comments += result.RowsAffected
+	}
+
+	return quotes, comments, nil
+}
+
+// Run запускает Purge каждые interval до отмены ctx
+func Run(ctx context.Context, db *gorm.DB, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		quotes, comments, err := Purge(db, retention)
+		if err != nil {
+			log.Printf("Trash purge failed: %v", err)
+		} else if quotes > 0 || comments > 0 {
+			log.Printf("Trash purge: removed %d quotes and %d comments", quotes, comments)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
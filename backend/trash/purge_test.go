@@ -0,0 +1,56 @@
+package trash
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestPurgeStatements(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type statement struct {
+		sql  string
+		vars []interface{}
+	}
+	var statements []statement
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, statement{tx.Statement.SQL.String(), tx.Statement.Vars})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	retention := 30 * 24 * time.Hour
+	before := time.Now().Add(-retention)
+	if _, _, err := Purge(db, retention); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	after := time.Now().Add(-retention)
+
+	// В DryRun ничего не удаляется, поэтому проход по комментариям ровно один
+	if len(statements) != 2 {
+		t.Fatalf("Purge() ran %d statements, want 2: %+v", len(statements), statements)
+	}
+	if !strings.HasPrefix(statements[0].sql, "DELETE FROM quotes") {
+		t.Errorf("first statement = %q, want quotes deletion", statements[0].sql)
+	}
+	if !strings.HasPrefix(statements[1].sql, "DELETE FROM comments") || !strings.Contains(statements[1].sql, "NOT EXISTS") {
+		t.Errorf("second statement = %q, want deletion of comments without replies", statements[1].sql)
+	}
+
+	for _, st := range statements {
+		if len(st.vars) != 1 {
+			t.Fatalf("statement %q vars = %v, want cutoff", st.sql, st.vars)
+		}
+		cutoff, ok := st.vars[0].(time.Time)
+		if !ok || cutoff.Before(before) || cutoff.After(after) {
+			t.Errorf("cutoff = %v, want between %v and %v", st.vars[0], before, after)
+		}
+	}
+}